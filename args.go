@@ -0,0 +1,292 @@
+package main
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+var userMention = regexp.MustCompile(`^<@!?(\d+)>$`)
+var channelMention = regexp.MustCompile(`^<#(\d+)>$`)
+var roleMention = regexp.MustCompile(`^<@&(\d+)>$`)
+var snowflake = regexp.MustCompile(`^\d+$`)
+
+// ArgRegion can be given as e.g. region:euw, which is never mistaken for the next argument
+const RegionPrefix = "region:"
+
+// the token without RegionPrefix, and whether it had it
+func trimRegionPrefix(token string) (string, bool) {
+    if len(token) > len(RegionPrefix) && strings.EqualFold(token[:len(RegionPrefix)], RegionPrefix) {
+        return token[len(RegionPrefix):], true
+    }
+    return token, false
+}
+
+func (e *ArgError) Error() string {
+    if e.Arg == nil {
+        return e.Reason
+    }
+    return fmt.Sprintf("`%v` %v", e.Arg.Title, e.Reason)
+}
+
+// returns the next token in s and whatever is left after it.
+// a token is either a single word or a "quoted string", with the quotes removed.
+func nextToken(s string) (token string, rest string, ok bool) {
+    s = strings.TrimLeftFunc(s, unicode.IsSpace)
+    if s == "" {
+        return "", "", false
+    }
+
+    if s[0] == '"' || strings.HasPrefix(s, "“") {
+        _, qlen := utf8.DecodeRuneInString(s)
+        end := strings.IndexAny(s[qlen:], "\"”")
+        if end != -1 {
+            _, endlen := utf8.DecodeRuneInString(s[qlen+end:])
+            return s[qlen:qlen+end], s[qlen+end+endlen:], true
+        }
+        // unterminated quote; just treat it as a normal word
+    }
+
+    end := strings.IndexFunc(s, unicode.IsSpace)
+    if end == -1 {
+        return s, "", true
+    }
+    return s[:end], s[end:], true
+}
+
+// riot's limits on the two halves of a Riot ID, in characters
+const (
+    MaxGameNameLength = 16
+    MinTagLength = 3
+    MaxTagLength = 5
+)
+
+// true if s could be the part of a Riot ID after the #
+func validRiotTag(s string) bool {
+    n := utf8.RuneCountInString(s)
+    if n < MinTagLength || n > MaxTagLength {
+        return false
+    }
+    for _, r := range(s) {
+        if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+            return false
+        }
+    }
+    return true
+}
+
+// like nextToken, but a Riot ID like Some Name#TAG is one token, spaces and all.
+// the # only counts if it's close enough to belong to the first argument (game names are at most
+// 16 characters) and what follows it is a valid tag; anything else, quoted strings included, works like nextToken.
+func nextRiotID(s string) (token string, rest string, ok bool) {
+    s = strings.TrimLeftFunc(s, unicode.IsSpace)
+    hash := strings.Index(s, "#")
+    if hash <= 0 || strings.ContainsAny(s[:hash], "\"“\n") || utf8.RuneCountInString(s[:hash]) > MaxGameNameLength {
+        return nextToken(s)
+    }
+    end := strings.IndexFunc(s[hash:], unicode.IsSpace)
+    if end == -1 {
+        end = len(s) - hash
+    }
+    if !validRiotTag(s[hash+1:hash+end]) {
+        return nextToken(s)
+    }
+    return s[:hash+end], s[hash+end:], true
+}
+
+// parses the text following a command's name according to the command's Args
+func (cmd *Command) ParseArgs(raw string) (*CommandArgs, error) {
+    args := &CommandArgs{
+        Raw: strings.TrimSpace(raw),
+        values: make(map[string]interface{}),
+    }
+
+    rest := args.Raw
+    for i := range(cmd.Args) {
+        arg := &cmd.Args[i]
+
+        if arg.Type == ArgRest {
+            rest = strings.TrimSpace(rest)
+            if rest == "" {
+                if arg.Required {
+                    return nil, &ArgError{ Arg: arg, Reason: "is required" }
+                }
+                continue
+            }
+            args.values[arg.Title] = rest
+            rest = ""
+            break
+        }
+
+        next := nextToken
+        if arg.Type == ArgSummoner {
+            next = nextRiotID
+        }
+        token, r, ok := next(rest)
+        if !ok {
+            if arg.Required {
+                return nil, &ArgError{ Arg: arg, Reason: "is required" }
+            }
+            continue
+        }
+        // a word that isn't a region is left for the next argument, unless it says it's a region
+        if arg.Type == ArgRegion && !arg.Required && i < len(cmd.Args) - 1 {
+            name, prefixed := trimRegionPrefix(token)
+            if !prefixed && FindLeagueRegion(name) == nil {
+                continue
+            }
+        }
+        rest = r
+
+        val, err := parseArg(arg, token)
+        if err != nil {
+            return nil, err
+        }
+        args.values[arg.Title] = val
+    }
+
+    // commands that don't take arguments never complain about extras
+    if len(cmd.Args) > 0 && strings.TrimSpace(rest) != "" {
+        return nil, &ArgError{ Reason: "Too many arguments." }
+    }
+
+    return args, nil
+}
+
+func parseArg(arg *CommandArg, token string) (interface{}, error) {
+    switch arg.Type {
+        case ArgInt:
+            n, err := strconv.Atoi(token)
+            if err != nil {
+                return nil, &ArgError{ Arg: arg, Reason: "must be a whole number" }
+            }
+            return n, nil
+        case ArgUser:
+            if m := userMention.FindStringSubmatch(token); m != nil {
+                return m[1], nil
+            }
+            if snowflake.MatchString(token) {
+                return token, nil
+            }
+            return nil, &ArgError{ Arg: arg, Reason: "must be a user mention" }
+        case ArgChannel:
+            if m := channelMention.FindStringSubmatch(token); m != nil {
+                return m[1], nil
+            }
+            if snowflake.MatchString(token) {
+                return token, nil
+            }
+            return nil, &ArgError{ Arg: arg, Reason: "must be a channel mention" }
+        case ArgRole:
+            if m := roleMention.FindStringSubmatch(token); m != nil {
+                return m[1], nil
+            }
+            if snowflake.MatchString(token) {
+                return token, nil
+            }
+            return nil, &ArgError{ Arg: arg, Reason: "must be a role mention" }
+        case ArgRegion:
+            token, _ = trimRegionPrefix(token)
+            if r := FindLeagueRegion(token); r != nil {
+                return r.Name, nil
+            }
+            return nil, &ArgError{ Arg: arg, Reason: "must be one of " + strings.Join(LeagueRegionNames(), ", ") }
+        default:
+            return token, nil
+    }
+}
+
+// the slash command equivalent of ParseArgs; discord has already split the options up for us
+func (cmd *Command) ArgsFromOptions(opts []*discordgo.ApplicationCommandInteractionDataOption) (*CommandArgs, error) {
+    args := &CommandArgs{
+        values: make(map[string]interface{}),
+    }
+
+    var raw []string
+    for i := range(cmd.Args) {
+        arg := &cmd.Args[i]
+
+        var opt *discordgo.ApplicationCommandInteractionDataOption
+        for _, o := range(opts) {
+            if o.Name == slashName(arg.Title) {
+                opt = o
+                break
+            }
+        }
+        if opt == nil {
+            if arg.Required {
+                return nil, &ArgError{ Arg: arg, Reason: "is required" }
+            }
+            continue
+        }
+
+        switch arg.Type {
+            case ArgInt:
+                args.values[arg.Title] = int(opt.IntValue())
+            default:
+                // users, channels and roles come through as their IDs
+                val, _ := opt.Value.(string)
+                val = strings.TrimSpace(val)
+                if val == "" {
+                    if arg.Required {
+                        return nil, &ArgError{ Arg: arg, Reason: "is required" }
+                    }
+                    continue
+                }
+                args.values[arg.Title] = val
+        }
+        raw = append(raw, fmt.Sprint(args.values[arg.Title]))
+    }
+
+    args.Raw = strings.Join(raw, " ")
+    return args, nil
+}
+
+// true if the argument was given
+func (a *CommandArgs) Has(name string) bool {
+    _, ok := a.values[name]
+    return ok
+}
+
+// returns "" if the argument wasn't given. also used for ArgUser, ArgChannel and ArgRole, which are stored as IDs,
+// and ArgRegion, which is stored as the region's short name
+func (a *CommandArgs) String(name string) string {
+    v, ok := a.values[name].(string)
+    if !ok {
+        return ""
+    }
+    return v
+}
+
+// returns 0 if the argument wasn't given
+func (a *CommandArgs) Int(name string) int {
+    v, ok := a.values[name].(int)
+    if !ok {
+        return 0
+    }
+    return v
+}
+
+// true if the command has an optional region that ParseArgs can leave for the next argument
+func (cmd *Command) SkipsRegion() bool {
+    for i, arg := range(cmd.Args) {
+        if arg.Type == ArgRegion && !arg.Required && i < len(cmd.Args) - 1 {
+            return true
+        }
+    }
+    return false
+}
+
+// e.g. "lol mastery <summoner> [champion]"
+func (cmd *Command) Usage() string {
+    usage := cmd.Name
+    for _, arg := range(cmd.Args) {
+        usage += fmt.Sprintf(" %s", arg)
+    }
+    return usage
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestNextToken(t *testing.T) {
+    // input -> token, rest
+    cases := map[string][2]string {
+        "one two": { "one", " two" },
+        "  one": { "one", "" },
+        `"two words" three`: { "two words", " three" },
+        "“curly quotes” rest": { "curly quotes", " rest" },
+        `"unterminated quote`: { `"unterminated`, " quote" },
+        `"" empty`: { "", " empty" },
+    }
+    for in, want := range(cases) {
+        token, rest, ok := nextToken(in)
+        if !ok || token != want[0] || rest != want[1] {
+            t.Errorf("nextToken(%q) = %q, %q, %v; want %q, %q", in, token, rest, ok, want[0], want[1])
+        }
+    }
+
+    for _, in := range([]string{ "", "   " }) {
+        if _, _, ok := nextToken(in); ok {
+            t.Errorf("nextToken(%q) found a token", in)
+        }
+    }
+}
+
+func TestNextRiotID(t *testing.T) {
+    // input -> token, rest
+    cases := map[string][2]string {
+        "faker#KR1": { "faker#KR1", "" },
+        "the tiny cactus#NA1 aatrox": { "the tiny cactus#NA1", " aatrox" },
+        "name#AB1CD rest": { "name#AB1CD", " rest" },
+        "miyari aatrox": { "miyari", " aatrox" },
+        `"some name" ahri`: { "some name", " ahri" },
+        // a quote before the # means the # belongs to a later argument
+        `miyari "kai#sa"`: { "miyari", ` "kai#sa"` },
+        // tags are 3 to 5 letters or numbers; anything else is just a word
+        "two words#AB rest": { "two", " words#AB rest" },
+        "two words#TOOLONG rest": { "two", " words#TOOLONG rest" },
+        "two words#A-1 rest": { "two", " words#A-1 rest" },
+        // game names are at most 16 characters, so a # further on isn't part of this one
+        "a name that is too long#NA1": { "a", " name that is too long#NA1" },
+    }
+    for in, want := range(cases) {
+        token, rest, ok := nextRiotID(in)
+        if !ok || token != want[0] || rest != want[1] {
+            t.Errorf("nextRiotID(%q) = %q, %q, %v; want %q, %q", in, token, rest, ok, want[0], want[1])
+        }
+    }
+}
+
+// the same arguments as lol matches, plus a user
+var testLeagueCommand = &Command{
+    Name: "test",
+    Args: []CommandArg {
+        { Title: "region", Type: ArgRegion },
+        { Title: "summoner", Type: ArgSummoner },
+        { Title: "count", Type: ArgInt },
+        { Title: "user", Type: ArgUser },
+    },
+}
+
+func parsed(t *testing.T, cmd *Command, raw string) map[string]interface{} {
+    t.Helper()
+    args, err := cmd.ParseArgs(raw)
+    if err != nil {
+        t.Fatalf("ParseArgs(%q) failed: %v", raw, err)
+    }
+    return args.values
+}
+
+func TestParseArgsRegion(t *testing.T) {
+    got := parsed(t, testLeagueCommand, "euw faker#KR1 5")
+    want := map[string]interface{}{ "region": "euw", "summoner": "faker#KR1", "count": 5 }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("with a region: got %v, want %v", got, want)
+    }
+
+    // a word that isn't a region is left for the summoner
+    got = parsed(t, testLeagueCommand, "faker 5")
+    want = map[string]interface{}{ "summoner": "faker", "count": 5 }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("without a region: got %v, want %v", got, want)
+    }
+
+    // a summoner named like a region needs region: in front of the real region
+    got = parsed(t, testLeagueCommand, "REGION:kr na")
+    want = map[string]interface{}{ "region": "kr", "summoner": "na" }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("with region:: got %v, want %v", got, want)
+    }
+
+    if _, err := testLeagueCommand.ParseArgs("region:nowhere faker"); err == nil {
+        t.Error("region: with a region that doesn't exist should fail")
+    }
+}
+
+func TestParseArgsTypes(t *testing.T) {
+    got := parsed(t, testLeagueCommand, "euw some name#EUW 5 <@!123>")
+    if got["summoner"] != "some name#EUW" || got["user"] != "123" {
+        t.Errorf("got %v", got)
+    }
+
+    for _, raw := range([]string{ "euw faker five", "euw faker 5 someone", "euw faker 5 123 extra" }) {
+        if _, err := testLeagueCommand.ParseArgs(raw); err == nil {
+            t.Errorf("ParseArgs(%q) should have failed", raw)
+        }
+    }
+}
+
+func TestParseArgsRest(t *testing.T) {
+    cmd := &Command{
+        Name: "rest",
+        Args: []CommandArg {
+            { Title: "channel", Required: true, Type: ArgChannel },
+            { Title: "message", Required: true, Type: ArgRest },
+        },
+    }
+    got := parsed(t, cmd, "<#456> hello   there ")
+    if got["channel"] != "456" || got["message"] != "hello   there" {
+        t.Errorf("got %v", got)
+    }
+    if _, err := cmd.ParseArgs("<#456>"); err == nil {
+        t.Error("a missing required ArgRest should fail")
+    }
+    if _, err := cmd.ParseArgs("general hello"); err == nil {
+        t.Error("a channel that isn't a mention or ID should fail")
+    }
+}
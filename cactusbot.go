@@ -7,7 +7,6 @@ import (
     "os"
     "os/signal"
     "log"
-    "regexp"
     "strings"
     "io"
 
@@ -29,12 +28,6 @@ var CommandEmbeds map[string]*discordgo.MessageEmbed
 var LeagueData LeagueHelper
 var EnableLOL = true
 
-var DadMatcher = regexp.MustCompile(`(?i)^i(['’]?m|\s+am)\s+\S`)
-var DadReplacer = regexp.MustCompile(`(?i)^i(['’]?m|\s+am)\s+`)
-var DadSanitizer = regexp.MustCompile(`(?i)@+(everyone|here)`)
-var DadEnabler = regexp.MustCompile(`(?i)^c\s+dad\s+(on|off)`)
-var EnableDad = false
-
 var bossnass = make([][]byte, 0)
 var hasbossnass = false
 
@@ -42,6 +35,13 @@ func init() {
     log.SetPrefix("[Cactusbot] ")
     log.Println("init: loading config")
     Config = LoadConfig()
+    log.Println("init: opening store")
+    var err error
+    DB, err = OpenStore(StoreFile)
+    if err != nil {
+        log.Printf("Error opening %v:\n%v\nNothing will be saved until this is fixed.\n", StoreFile, err)
+        DB = NewMemoryStore()
+    }
     if Config.LeagueToken == "" {
         log.Println("League token not found; 'lol' commands will be disabled.")
         EnableLOL = false
@@ -51,11 +51,12 @@ func init() {
             EnableLOL = false
         }
     }
+    IndexCommands()
     log.Println("init: creating help embeds")
     InitHelpEmbed(&HelpEmbed)
     CommandEmbeds = make(map[string]*discordgo.MessageEmbed)
     InitCommandEmbeds(CommandEmbeds)
-    err := loadsound()
+    err = loadsound()
     if err != nil {
         hasbossnass = false
         log.Println("No boss nass.");
@@ -94,8 +95,12 @@ func main() {
         return
     }
 
+    // message content is a privileged intent now, and text commands need it
+    dg.Identify.Intents = discordgo.IntentsAllWithoutPrivileged | discordgo.IntentsMessageContent
+
     dg.AddHandler(ready)
     dg.AddHandler(messageCreate)
+    dg.AddHandler(interactionCreate)
     dg.AddHandler(connect)
     dg.AddHandler(resume)
     dg.AddHandler(disconnect)
@@ -110,11 +115,19 @@ func main() {
 
     if EnableLOL {
         go LeagueData.UpdateRoutine()
+        go LeagueData.Client.Cache.PruneRoutine()
+        go LeagueStatsData.RefreshRoutine()
+        go RankWatchRoutine(dg)
     }
+    go Limiter.PruneRoutine()
 
     SigChan = make(chan os.Signal)
     signal.Notify(SigChan, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
     <-SigChan
+
+    if EnableLOL {
+        LeagueData.Client.Cache.Prune()
+    }
 }
 
 func ready(s *discordgo.Session, event *discordgo.Ready) {
@@ -126,9 +139,11 @@ func ready(s *discordgo.Session, event *discordgo.Ready) {
         IdleSince: &i,
         AFK: false,
         Status: "online",
-        Game: &discordgo.Game {
-            Name: "you OwO",
-            Type: discordgo.GameTypeWatching,
+        Activities: []*discordgo.Activity {
+            {
+                Name: "you OwO",
+                Type: discordgo.ActivityTypeWatching,
+            },
         },
     }
 
@@ -136,6 +151,8 @@ func ready(s *discordgo.Session, event *discordgo.Ready) {
     if err != nil {
         log.Printf("Error in ready:\n%v\n", err)
     }
+
+    RegisterSlashCommands(s)
 }
 
 func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
@@ -143,35 +160,17 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
     if m.Author.Bot {
         return
     }
+    defer recoverEvent(s, "messageCreate", m.Content)
 
-    if Config.IsAdmin(m.Author.ID) && DadEnabler.MatchString(m.Content) {
-        parts := strings.Split(m.Content, " ")
-        parts[2] = strings.ToLower(parts[2])
-        if parts[2] == "off" {
-            EnableDad = false
-        } else if parts[2] == "on" {
-            EnableDad = true
-        }
-        _, err := s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("```golang\nEnableDad = %t```", EnableDad))
-        if err != nil {
-            log.Printf("Error in messageCreate:\n%v\n", err)
-        }
-    }
-
-    if EnableDad && DadMatcher.MatchString(m.Content) {
-        response := DadReplacer.ReplaceAllString(m.Content, "")
-        response = DadSanitizer.ReplaceAllString(response, "$1")
-        _, err := s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Hi %s, I'm cactusbot!", response))
-        if err != nil {
-            log.Printf("Error in messageCreate:\n%v\n", err)
-        }
-    }
-
-    for _, cmd := range(Commands) {
-        if cmd.Pattern.MatchString(m.Content) {
-            cmd.Handle(m, s)
-            break
+    if content, prefix, ok := StripPrefix(s, m); ok {
+        for i := range(Commands) {
+            if Commands[i].Pattern.MatchString(content) {
+                Commands[i].Handle(m, s, content, prefix)
+                break
+            }
         }
+    } else {
+        DadJoke(s, m)
     }
 
     if !(Config.LogChannel == "" || Config.LogWebhookID == "" || Config.LogWebhookToken == "") {
@@ -192,7 +191,7 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
             }
             _, err := s.WebhookExecute(Config.LogWebhookID, Config.LogWebhookToken, false, &whp)
             if err != nil {
-                log.Println("Error in messageCreate:\n%v\n", err)
+                log.Printf("Error in messageCreate:\n%v\n", err)
             }
         }
     }
@@ -219,9 +218,11 @@ func resume(s *discordgo.Session, event *discordgo.Resumed) {
         IdleSince: &i,
         AFK: false,
         Status: "online",
-        Game: &discordgo.Game {
-            Name: "you OwO",
-            Type: discordgo.GameTypeWatching,
+        Activities: []*discordgo.Activity {
+            {
+                Name: "you OwO",
+                Type: discordgo.ActivityTypeWatching,
+            },
         },
     }
 
@@ -2,58 +2,55 @@ package main
 
 import (
     "github.com/bwmarrin/discordgo"
-    "regexp"
     "log"
     "math/rand"
     "fmt"
     "time"
     "strconv"
     "syscall"
+    "sort"
     "strings"
+    "sync"
 )
 
-func oodlehandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    re := regexp.MustCompile(`(?i)^c(actus)?\s+oodle\s+`)
-    cleanmsg := re.ReplaceAllString(msg.Content, "")
-    m := oodle(cleanmsg)
+func oodlehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    m := oodle(args.String("message"))
     if len(m) > 2000 {
         m = "Your message is too long. Sorry!"
     }
-    _, err := s.ChannelMessageSend(msg.ChannelID, m)
+    _, err := ctx.Reply(m)
     if err != nil {
         log.Printf("Error in oodlehandler:\n%v\n", err)
     }
 }
 
-func echohandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    re := regexp.MustCompile(`(?i)^c(actus)?\s+echo\s+`)
-    cleanmsg := re.ReplaceAllString(msg.Content, "")
+func echohandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    cleanmsg := args.String("message")
     if len(cleanmsg) > 2000 {
         cleanmsg = "Your message is too long. Sorry!"
     }
-    _, err := s.ChannelMessageSend(msg.ChannelID, cleanmsg)
+    _, err := ctx.ReplyNoMentions(cleanmsg)
     if err != nil {
         log.Printf("Error in echohandler:\n%v\n", err)
     }
 }
 
-func oodlettshandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    re := regexp.MustCompile(`(?i)^c(actus)?\s+oodletts\s+`)
-    cleanmsg := re.ReplaceAllString(msg.Content, "")
+func oodlettshandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    cleanmsg := args.String("message")
 
     // check that the user has permission to use TTS, otherwise this will go poorly
-    perms, err := s.State.UserChannelPermissions(msg.Author.ID, msg.ChannelID)
+    perms, err := ctx.AuthorPermissions()
     if err != nil {
-        _, err = s.ChannelMessageSend(msg.ChannelID, "Something went wrong, please try again later. Sorry! :(")
+        _, err = ctx.Reply("Something went wrong, please try again later. Sorry! :(")
     } else {
         if (perms & discordgo.PermissionSendTTSMessages) > 0 {
             cleanmsg = oodle(cleanmsg)
             if len(cleanmsg) > 2000 {
                 cleanmsg = "Your message is too long. Sorry!"
             }
-            _, err = s.ChannelMessageSendTTS(msg.ChannelID, cleanmsg)
+            _, err = ctx.ReplyTTS(cleanmsg)
         } else {
-            _, err = s.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("Sorry <@%v>, you don't have permission to use TTS. Here's a normal one:\n%v", msg.Author.ID, oodle(cleanmsg)))
+            _, err = ctx.Reply(fmt.Sprintf("Sorry <@%v>, you don't have permission to use TTS. Here's a normal one:\n%v", ctx.Author.ID, oodle(cleanmsg)))
 
         }
     }
@@ -65,122 +62,100 @@ func oodlettshandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
 
 var s1 = rand.NewSource(time.Now().UnixNano())
 var r1 = rand.New(s1)
+var r1Lock sync.Mutex
 
-func coinfliphandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    val := r1.Intn(2) // get a random number in [0, 2), so either 0 or 1
+// r1.Intn, but safe to call from several handlers at once
+func randIntn(n int) int {
+    r1Lock.Lock()
+    defer r1Lock.Unlock()
+    return r1.Intn(n)
+}
+
+func coinfliphandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    val := randIntn(2) // get a random number in [0, 2), so either 0 or 1
     var result string
     if val == 0 {
         result = "Heads!"
     } else {
         result = "Tails!"
     }
-    _, err := s.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("**%v**", result))
+    _, err := ctx.Reply(fmt.Sprintf("**%v**", result))
     if err != nil {
         log.Printf("Error in coinfliphandler:\n%v\n", err)
     }
 }
 
-func rollhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    cleanre := regexp.MustCompile(`(?i)^c(actus)?\s+roll\s*`)
-    clean := strings.TrimSpace(cleanre.ReplaceAllString(msg.Content, ""))
-    if clean == "" {
-        val := r1.Intn(6) + 1
-        _, err := s.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("You rolled: %v", val))
-        if err != nil {
-            log.Printf("Error in rollhandler:\n%v\n", err)
-        }
-    } else {
-        // split the string and get the arguments
-        args := strings.FieldsFunc(clean, func(r rune) bool {
-            return r == ' ' || r == 'd'
-        })
-        if len(args) == 1 {
-            sides, _ := strconv.Atoi(args[0])
-            if sides == 0 {
-                _, err := s.ChannelMessageSend(msg.ChannelID, "Please enter a valid number of sides.")
-                if err != nil {
-                    log.Printf("Error in rollhandler:\n%v\n", err)
-                }
-                return
-            }
-            val := r1.Intn(sides) + 1
-            _, err := s.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("You rolled: %v", val))
-            if err != nil {
-                log.Printf("Error in rollhandler:\n%v\n", err)
-            }
-        } else if len(args) >= 2 {
-            sides, _ := strconv.Atoi(args[1])
-            num, _ := strconv.Atoi(args[0])
-            if num == 0 {
-                _, err := s.ChannelMessageSend(msg.ChannelID, "Please enter a valid number of dice.")
-                if err != nil {
-                    log.Printf("Error in rollhandler:\n%v\n", err)
-                }
-                return
-            }
-            if sides == 0 {
-                _, err := s.ChannelMessageSend(msg.ChannelID, "Please enter a valid number of sides.")
-                if err != nil {
-                    log.Printf("Error in rollhandler:\n%v\n", err)
-                }
-                return
-            }
-            var rolls []string
-            for i := 0; i < num; i++ {
-                rolls = append(rolls, strconv.Itoa(r1.Intn(sides) + 1))
+func rollhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    dice := args.String("dice")
+    if dice == "" {
+        dice = "d6"
+    }
+
+    var reply string
+    x, label, err := ParseDice(dice)
+    if err == nil {
+        var total int
+        var shown string
+        total, shown, err = RollDice(x)
+        if err == nil {
+            if label != "" {
+                label = " for " + label
             }
-            replymsg := fmt.Sprintf("You rolled: %v", strings.Join(rolls, ", "))
-            if len(replymsg) > 2000 {
-                replymsg = "Cannot fit that many dice into one message!"
-            }
-            _, err := s.ChannelMessageSend(msg.ChannelID, replymsg)
-            if err != nil {
-                log.Printf("Error in rollhandler:\n%v\n", err)
+            reply = fmt.Sprintf("You rolled **%v**%v: %v", total, label, shown)
+            if len(reply) > 2000 {
+                reply = fmt.Sprintf("You rolled **%v**%v. (That's too many dice to show them all!)", total, label)
             }
         }
     }
+    if err != nil {
+        reply = fmt.Sprintf("Sorry, I can't roll that: %v. Try `%vhelp roll` for examples.", err, ctx.Prefix)
+    }
+
+    _, err = ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in rollhandler:\n%v\n", err)
+    }
 }
 
-func blocklettershandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    re := regexp.MustCompile(`(?i)^c(actus)?\s+bl(ockletters)?\s+`)
-    cleanmsg := re.ReplaceAllString(msg.Content, "")
-    cleanmsg = texttoemotes(cleanmsg)
+func blocklettershandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    cleanmsg := texttoemotes(args.String("message"))
     if len(cleanmsg) > 2000 {
         cleanmsg = "Your message is too long. Sorry!"
     }
-    _, err := s.ChannelMessageSend(msg.ChannelID, cleanmsg)
+    _, err := ctx.Reply(cleanmsg)
     if err != nil {
         log.Printf("Error in blocklettershandler:\n%v\n", err)
     }
 }
 
-func invitehandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
+func invitehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
     inv := fmt.Sprintf("Use this link to invite me to your server: " + InvURL, Config.DiscordClientID, Perms)
-    _, err := s.ChannelMessageSend(msg.ChannelID, inv)
+    _, err := ctx.Reply(inv)
     if err != nil {
         log.Printf("Error in invitehandler:\n%v\n", err)
     }
 }
 
-func helphandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    re := regexp.MustCompile(`(?i)^c(actus)?\s+help\s*`)
-    clean := re.ReplaceAllString(msg.Content, "")
-    embedcolor := s.State.UserColor(s.State.User.ID, msg.ChannelID)
+func helphandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    embedcolor := s.State.UserColor(s.State.User.ID, ctx.ChannelID)
     
-    if clean == "" {
+    if !args.Has("command") {
         embed := HelpEmbed
         embed.Color = embedcolor
+        if gp := GetGuildPrefix(ctx.GuildID); gp != "" {
+            embed.Description = HelpDescription(displayPrefix(gp))
+        }
 
-        _, err := s.ChannelMessageSendEmbed(msg.ChannelID, &embed)
+        _, err := ctx.ReplyEmbed(&embed)
         if err != nil {
             log.Printf("Error in helphandler:\n%v\n", err)
         }
     } else {
-        arg := strings.TrimSpace(strings.ToLower(clean))
+        arg := strings.ToLower(args.String("command"))
         // need to avoid referencing commands.go in here
         embed, ok := CommandEmbeds[arg]
         if !ok {
-            _, err := s.ChannelMessageSend(msg.ChannelID, "Command not found: " + arg)
+            _, err := ctx.Reply("Command not found: " + arg)
             if err != nil {
                 log.Printf("Error in helphandler:\n%v\n", err)
             }
@@ -188,7 +163,7 @@ func helphandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
         } else {
             e := *embed
             e.Color = embedcolor
-            _, err := s.ChannelMessageSendEmbed(msg.ChannelID, &e)
+            _, err := ctx.ReplyEmbed(&e)
             if err != nil {
                 log.Printf("Error in helphandler:\n%v\n", err)
             }
@@ -197,13 +172,298 @@ func helphandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
     }
 }
 
-func shutdownhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
+func prefixhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    _, err := ctx.Reply(fmt.Sprintf("The prefix here is `%v`.", strings.TrimSpace(GuildDisplayPrefix(ctx.GuildID))))
+    if err != nil {
+        log.Printf("Error in prefixhandler:\n%v\n", err)
+    }
+}
+
+func prefixsethandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var reply string
+    newprefix := args.String("new prefix")
+    if len(newprefix) > MaxPrefixLength || strings.ContainsAny(newprefix, "`@#<>") {
+        reply = fmt.Sprintf("Prefixes can be at most %v characters long and can't contain any of `` ` @ # < > ``.", MaxPrefixLength)
+    } else if err := SetGuildPrefix(ctx.GuildID, newprefix); err != nil {
+        log.Printf("Error in prefixsethandler:\n%v\n", err)
+        reply = "Something went wrong, please try again later. Sorry! :("
+    } else {
+        reply = fmt.Sprintf("The prefix is now `%v`. For example: `%vhelp`", newprefix, displayPrefix(newprefix))
+    }
+
+    _, err := ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in prefixsethandler:\n%v\n", err)
+    }
+}
+
+func prefixresethandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    reply := fmt.Sprintf("The prefix is back to %v.", DefaultPrefixList("and"))
+    err := SetGuildPrefix(ctx.GuildID, "")
+    if err != nil {
+        log.Printf("Error in prefixresethandler:\n%v\n", err)
+        reply = "Something went wrong, please try again later. Sorry! :("
+    }
+
+    _, err = ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in prefixresethandler:\n%v\n", err)
+    }
+}
+
+func dadstatushandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    ds := GetGuildSettings(ctx.GuildID).Dad
+    embed := ds.StatusEmbed(ctx.ChannelID)
+    embed.Color = s.State.UserColor(s.State.User.ID, ctx.ChannelID)
+    if GetUserData(ctx.Author.ID).DadOptOut {
+        embed.Footer = &discordgo.MessageEmbedFooter{
+            Text: "You've opted out, so I'll never reply to you.",
+        }
+    }
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in dadstatushandler:\n%v\n", err)
+    }
+}
+
+// handles both "dad optout" and "dad optin"
+func dadopthandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    optout := ctx.Command.Name == "dad optout"
+    var reply string
+    err := UpdateUserData(ctx.Author.ID, func(ud *UserData) {
+        ud.DadOptOut = optout
+    })
+    if err != nil {
+        log.Printf("Error in dadopthandler:\n%v\n", err)
+        reply = "Something went wrong, please try again later. Sorry! :("
+    } else if optout {
+        reply = "Okay, I'll never reply to your messages in dad mode."
+    } else {
+        reply = "Okay, I'll reply to your messages in dad mode again."
+    }
+
+    _, err = ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in dadopthandler:\n%v\n", err)
+    }
+}
+
+func dadsethandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    setting := strings.ToLower(args.String("setting"))
+    value := strings.ToLower(args.String("value"))
+
+    var reply string
+    var update func(*GuildSettings)
+    switch setting {
+        case "on", "off":
+            enabled := setting == "on"
+            if value == "here" {
+                update = func(gs *GuildSettings) {
+                    if gs.Dad.Channels == nil {
+                        gs.Dad.Channels = make(map[string]bool)
+                    }
+                    gs.Dad.Channels[ctx.ChannelID] = enabled
+                }
+                reply = fmt.Sprintf("Dad mode is now %v in this channel.", setting)
+            } else {
+                update = func(gs *GuildSettings) {
+                    gs.Dad.Enabled = enabled
+                    gs.Dad.Channels = nil
+                }
+                reply = fmt.Sprintf("Dad mode is now %v for the whole server.", setting)
+            }
+        case "chance":
+            chance, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+            if err != nil || chance < 1 || chance > 100 {
+                reply = "The chance has to be a percentage from 1 to 100."
+            } else {
+                update = func(gs *GuildSettings) {
+                    gs.Dad.Chance = &chance
+                }
+                reply = fmt.Sprintf("I'll now reply to %v%% of messages in dad mode.", chance)
+            }
+        case "cooldown":
+            cooldown, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+            if err != nil || cooldown < 0 || cooldown > MaxDadCooldown {
+                reply = "The cooldown has to be a number of seconds, up to a day."
+            } else {
+                update = func(gs *GuildSettings) {
+                    gs.Dad.Cooldown = &cooldown
+                }
+                reply = fmt.Sprintf("I'll now wait at least %v between dad jokes in each channel.", time.Duration(cooldown) * time.Second)
+            }
+        default:
+            reply = fmt.Sprintf("Unknown setting: %v. Try one of `on`, `off`, `chance`, or `cooldown`.", setting)
+    }
+
+    if update != nil {
+        if err := UpdateGuildSettings(ctx.GuildID, update); err != nil {
+            log.Printf("Error in dadsethandler:\n%v\n", err)
+            reply = "Something went wrong, please try again later. Sorry! :("
+        }
+    }
+
+    _, err := ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in dadsethandler:\n%v\n", err)
+    }
+}
+
+// lists role mentions, or "nobody". only for embeds, where mentions don't ping
+func roleList(roles []string) string {
+    if len(roles) == 0 {
+        return "nobody"
+    }
+    mentions := make([]string, len(roles))
+    for i, r := range(roles) {
+        mentions[i] = "<@&" + r + ">"
+    }
+    return strings.Join(mentions, " ")
+}
+
+// the role's name without mentioning it, since that would ping everyone in it
+func roleName(s *discordgo.Session, guildID, roleID string) string {
+    role, err := s.State.Role(guildID, roleID)
+    if err != nil {
+        return "That role"
+    }
+    return "**" + strings.ReplaceAll(role.Name, "@", "") + "**"
+}
+
+func permslisthandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    roles := GetGuildSettings(ctx.GuildID).Roles
+    embed := &discordgo.MessageEmbed{
+        Title: "Command Permissions",
+        Color: s.State.UserColor(s.State.User.ID, ctx.ChannelID),
+    }
+
+    var names []string
+    if args.Has("command") {
+        cmd := FindCommand(args.String("command"))
+        if cmd == nil {
+            _, err := ctx.Reply(fmt.Sprintf("There's no command called `%v`.", args.String("command")))
+            if err != nil {
+                log.Printf("Error in permslisthandler:\n%v\n", err)
+            }
+            return
+        }
+        names = append(names, cmd.Name)
+    } else {
+        for name := range(roles) {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        if len(names) == 0 {
+            embed.Description = "No commands have role rules here, so they all use their default permissions."
+        }
+    }
+
+    for _, name := range(names) {
+        value := ""
+        if cmd := FindCommand(name); cmd != nil && cmd.Permissions != 0 {
+            value += fmt.Sprintf("Requires: %v\n", PermissionString(cmd.Permissions))
+        }
+        if rr := roles[name]; rr != nil {
+            value += fmt.Sprintf("Allowed: %v\nDenied: %v", roleList(rr.Allow), roleList(rr.Deny))
+        } else {
+            value += "No role rules."
+        }
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: name,
+            Value: value,
+            Inline: false,
+        })
+    }
+    // discord only allows 25 fields
+    if len(embed.Fields) > 25 {
+        embed.Fields = embed.Fields[:25]
+    }
+
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in permslisthandler:\n%v\n", err)
+    }
+}
+
+// handles both "perms allow" and "perms deny"
+func permssethandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    allow := ctx.Command.Name == "perms allow"
+    role := args.String("role")
+
+    var reply string
+    cmd := FindCommand(args.String("command"))
+    if cmd == nil {
+        reply = fmt.Sprintf("There's no command called `%v`.", args.String("command"))
+    } else if cmd.AdminOnly {
+        reply = "Sorry, bot admin commands can't be given to roles."
+    } else if role == ctx.GuildID && !allow {
+        // @everyone's role ID is the guild ID; denying it would lock out everyone but server managers
+        reply = "Sorry, you can't deny @everyone. Allow the roles that should use it instead."
+    } else {
+        err := UpdateGuildSettings(ctx.GuildID, func(gs *GuildSettings) {
+            if gs.Roles == nil {
+                gs.Roles = make(map[string]*RoleRules)
+            }
+            if gs.Roles[cmd.Name] == nil {
+                gs.Roles[cmd.Name] = &RoleRules{}
+            }
+            gs.Roles[cmd.Name].Set(role, allow)
+        })
+        if err != nil {
+            log.Printf("Error in permssethandler:\n%v\n", err)
+            reply = "Something went wrong, please try again later. Sorry! :("
+        } else if allow {
+            reply = fmt.Sprintf("%v can now use `%v`.", roleName(s, ctx.GuildID, role), cmd.Name)
+        } else {
+            reply = fmt.Sprintf("%v can no longer use `%v`.", roleName(s, ctx.GuildID, role), cmd.Name)
+        }
+    }
+
+    _, err := ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in permssethandler:\n%v\n", err)
+    }
+}
+
+func permsresethandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var reply string
+    cmd := FindCommand(args.String("command"))
+    if cmd == nil {
+        reply = fmt.Sprintf("There's no command called `%v`.", args.String("command"))
+    } else {
+        err := UpdateGuildSettings(ctx.GuildID, func(gs *GuildSettings) {
+            delete(gs.Roles, cmd.Name)
+        })
+        if err != nil {
+            log.Printf("Error in permsresethandler:\n%v\n", err)
+            reply = "Something went wrong, please try again later. Sorry! :("
+        } else {
+            reply = fmt.Sprintf("`%v` is back to its default permissions.", cmd.Name)
+        }
+    }
+
+    _, err := ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in permsresethandler:\n%v\n", err)
+    }
+}
+
+func metricshandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    embed := MetricsEmbed()
+    embed.Color = s.State.UserColor(s.State.User.ID, ctx.ChannelID)
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in metricshandler:\n%v\n", err)
+    }
+}
+
+func shutdownhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
     if Config.ControllerID != "" {
-        s.ChannelMessageSend(msg.ChannelID, "This bot is running with a controller. You must shut it down from the controller instead.")
+        ctx.Reply("This bot is running with a controller. You must shut it down from the controller instead.")
         return
     }
 
-    _, err := s.ChannelMessageSend(msg.ChannelID, "Goodbye!")
+    _, err := ctx.Reply("Goodbye!")
     if err != nil {
         log.Printf("Error in shutdownhandler:\n%v\n", err)
     }
@@ -211,23 +471,23 @@ func shutdownhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
     SigChan <- syscall.SIGINT
 }
 
-func sownerhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    guild, err := s.Guild(msg.GuildID)
+func sownerhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    guild, err := s.Guild(ctx.GuildID)
     if err != nil {
         log.Printf("Error in sownerhandler:\n%v\n", err)
         return
     }
 
-    _, err = s.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("The owner of this server is <@%v>", guild.OwnerID))
+    _, err = ctx.Reply(fmt.Sprintf("The owner of this server is <@%v>", guild.OwnerID))
     if err != nil {
         log.Printf("Error in sownerhandler:\n%v\n", err)
     }
 }
 
-func srchandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
+func srchandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
     srcembed := &discordgo.MessageEmbed{
         URL: RepoURL,
-        Color: s.State.UserColor(s.State.User.ID, msg.ChannelID),
+        Color: s.State.UserColor(s.State.User.ID, ctx.ChannelID),
         Title: "Repo: willeccles/cactusbot",
         Description: "The source code for the cactus bot!",
         Author: &discordgo.MessageEmbedAuthor {
@@ -242,35 +502,30 @@ func srchandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
             },
         },
     }
-    _, err := s.ChannelMessageSendEmbed(msg.ChannelID, srcembed)
+    _, err := ctx.ReplyEmbed(srcembed)
     if err != nil {
         log.Printf("Error in srchandler:\n%v\n", err)
     }
 }
 
-func xkcdhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    re := regexp.MustCompile(`\D`) // just get rid of anything that's not a number
-    cleanmsg := re.ReplaceAllString(msg.Content, "")
-    comicnum := 0
-    if cleanmsg != "" {
-        comicnum, _ = strconv.Atoi(cleanmsg)
-    }
+func xkcdhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    comicnum := args.Int("number")
 
     n, t, a, i, e := GetXkcd(comicnum)
     if e.ErrType != 0 {
         switch e.ErrType {
             case XkcdNotFound:
-                _, err := s.ChannelMessageSend(msg.ChannelID, fmt.Sprintf("Error: xkcd #%v doesn't exist.", comicnum))
+                _, err := ctx.Reply(fmt.Sprintf("Error: xkcd #%v doesn't exist.", comicnum))
                 if err != nil {
                     log.Printf("Error in xkcdhandler:\n%v\n", err)
                 }
             case XkcdNetworkErr:
-                _, err := s.ChannelMessageSend(msg.ChannelID, "Error getting xkcd info. Please try again later.")
+                _, err := ctx.Reply("Error getting xkcd info. Please try again later.")
                 if err != nil {
                     log.Printf("Error in xkcdhandler:\n%v\n", err)
                 }
             case XkcdOtherErr:
-                _, err := s.ChannelMessageSend(msg.ChannelID, "Error getting xkcd info. Please try again later.")
+                _, err := ctx.Reply("Error getting xkcd info. Please try again later.")
                 if err != nil {
                     log.Printf("Error in xkcdhandler:\n%v\n", err)
                 }
@@ -285,7 +540,7 @@ func xkcdhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
 
     xkcdembed := &discordgo.MessageEmbed{
         URL: url,
-        Color: s.State.UserColor(s.State.User.ID, msg.ChannelID),
+        Color: s.State.UserColor(s.State.User.ID, ctx.ChannelID),
         Title: fmt.Sprintf("#%v: **%v**", n, t),
         Image: &discordgo.MessageEmbedImage{
             URL: i,
@@ -295,96 +550,395 @@ func xkcdhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
         },
     }
 
-    _, err := s.ChannelMessageSendEmbed(msg.ChannelID, xkcdembed)
+    _, err := ctx.ReplyEmbed(xkcdembed)
     if err != nil {
         log.Printf("Error in xkcdhandler:\n%v\n", err)
     }
 }
 
-var lolstat = regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+status\s+`)
-var lolprofile = regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+p(rofile)?\s+`)
-var lolmasteries = regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+m(astery)?\s+`)
-var lolchamp = regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+c(hamp(ion)?)?\s+`)
-
-func lolprofilehandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    if !EnableLOL {
-        _, err := s.ChannelMessageSend(msg.ChannelID, "Sorry, but League commands are disabled due to a configuration issue. Check back later.")
+func lolprofilehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    region, who, err := leagueTarget(ctx, args.String("summoner"), args.String("region"))
+    if err != nil {
+        _, err = ctx.Reply(err.Error())
         if err != nil {
             log.Printf("Error in lolprofilehandler:\n%v\n", err)
         }
         return
     }
-    embed := LeagueData.GetSummonerEmbed(lolprofile.ReplaceAllString(msg.Content, ""))
-    _, err := s.ChannelMessageSendEmbed(msg.ChannelID, embed)
+    embed := LeagueData.GetSummonerEmbed(region, who)
+    _, err = ctx.ReplyEmbed(embed)
     if err != nil {
         log.Printf("Error in lolprofilehandler:\n%v\n", err)
     }
 }
 
-func lolmasteryhandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    if !EnableLOL {
-        _, err := s.ChannelMessageSend(msg.ChannelID, "Sorry, but League commands are disabled due to a configuration issue. Check back later.")
+func lolmasteryhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    // "lol mastery aatrox" means their own mastery on aatrox, if they've linked an account
+    name, champion := args.String("summoner"), args.String("champion")
+    if champion == "" && !strings.Contains(name, "#") && GetLeagueLink(ctx.Author.ID) != nil {
+        if champ, _ := LeagueData.ResolveChampion(name); champ != nil {
+            name, champion = "", name
+        }
+    }
+    region, who, err := leagueTarget(ctx, name, args.String("region"))
+    if err != nil {
+        _, err = ctx.Reply(err.Error())
         if err != nil {
             log.Printf("Error in lolmasteryhandler:\n%v\n", err)
         }
         return
     }
-    args := lolmasteries.ReplaceAllString(msg.Content, "")
-    argsplit := strings.Split(args, " ")
-    if len(argsplit) == 1 {
-        embed := LeagueData.GetSummonerMasteriesEmbed(argsplit[0])
-        _, err := s.ChannelMessageSendEmbed(msg.ChannelID, embed)
+    if champion == "" {
+        embed := LeagueData.GetSummonerMasteriesEmbed(region, who)
+        _, err := ctx.ReplyEmbed(embed)
         if err != nil {
             log.Printf("Error in lolmasteryhandler:\n%v\n", err)
         }
     } else {
-        champname := ""
-        for i := 1; i < len(argsplit); i++ {
-            champname += argsplit[i]
-        }
-        embed := LeagueData.GetSummonerMasteryEmbed(argsplit[0], champname)
-        _, err := s.ChannelMessageSendEmbed(msg.ChannelID, embed)
+        embed := LeagueData.GetSummonerMasteryEmbed(region, who, champion)
+        _, err := ctx.ReplyEmbed(embed)
         if err != nil {
             log.Printf("Error in lolmasteryhandler:\n%v\n", err)
         }
     }
 }
 
-func lolchamphandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    champ := lolchamp.ReplaceAllString(msg.Content, "")
-    embed := LeagueData.GetChampionEmbed(champ)
-    _, err := s.ChannelMessageSendEmbed(msg.ChannelID, embed)
+func lolchamphandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    champion, level := args.String("champion"), 0
+    if args.Has("level") {
+        level = args.Int("level")
+    } else if !ctx.IsInteraction() {
+        // text commands can't tell the level apart from the name, so it's the last word
+        champion, level = splitChampionLevel(champion)
+    }
+    // 0 means no level was given, so it only counts as one when it was typed in
+    if level < 0 || level > MaxChampionLevel || (level == 0 && args.Has("level")) {
+        _, err := ctx.Reply(fmt.Sprintf("Champions can only be level 1 to %v.", MaxChampionLevel))
+        if err != nil {
+            log.Printf("Error in lolchamphandler:\n%v\n", err)
+        }
+        return
+    }
+    embed := LeagueData.GetChampionEmbed(champion, level)
+    _, err := ctx.ReplyEmbed(embed)
     if err != nil {
         log.Printf("Error in lolchamphandler:\n%v\n", err)
     }
 }
 
-func lolstatushandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    embed := LeagueData.GetStatusEmbed()
-    _, err := s.ChannelMessageSendEmbed(msg.ChannelID, embed)
+func lolcomparehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    level := 1
+    if args.Has("level") {
+        level = args.Int("level")
+    }
+    if level < 1 || level > MaxChampionLevel {
+        _, err := ctx.Reply(fmt.Sprintf("Champions can only be level 1 to %v.", MaxChampionLevel))
+        if err != nil {
+            log.Printf("Error in lolcomparehandler:\n%v\n", err)
+        }
+        return
+    }
+    embed := LeagueData.GetChampionCompareEmbed(args.String("first champion"), args.String("second champion"), level)
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lolcomparehandler:\n%v\n", err)
+    }
+}
+
+func lolitemhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    embed := LeagueData.GetItemEmbed(args.String("item"))
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lolitemhandler:\n%v\n", err)
+    }
+}
+
+func lolrunehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    embed := LeagueData.GetRuneEmbed(args.String("rune"))
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lolrunehandler:\n%v\n", err)
+    }
+}
+
+func lolmatcheshandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    // "lol matches 10" means their own last 10 games
+    name := args.String("summoner")
+    count := DefaultMatchCount
+    if args.Has("count") {
+        count = args.Int("count")
+    } else if n, err := strconv.Atoi(name); err == nil {
+        name, count = "", n
+    }
+    if count < 1 || count > MaxMatchCount {
+        _, err := ctx.Reply(fmt.Sprintf("You can see between 1 and %v matches at a time.", MaxMatchCount))
+        if err != nil {
+            log.Printf("Error in lolmatcheshandler:\n%v\n", err)
+        }
+        return
+    }
+    region, who, err := leagueTarget(ctx, name, args.String("region"))
+    if err != nil {
+        _, err = ctx.Reply(err.Error())
+        if err != nil {
+            log.Printf("Error in lolmatcheshandler:\n%v\n", err)
+        }
+        return
+    }
+    embed := LeagueData.GetMatchesEmbed(region, who, count)
+    _, err = ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lolmatcheshandler:\n%v\n", err)
+    }
+}
+
+func lollivehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    region, who, err := leagueTarget(ctx, args.String("summoner"), args.String("region"))
+    if err != nil {
+        _, err = ctx.Reply(err.Error())
+        if err != nil {
+            log.Printf("Error in lollivehandler:\n%v\n", err)
+        }
+        return
+    }
+    embed := LeagueData.GetLiveGameEmbed(region, who)
+    _, err = ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lollivehandler:\n%v\n", err)
+    }
+}
+
+func lolmatchhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    region := LeagueData.Region(ctx.GuildID, args.String("region"))
+    embed := LeagueData.GetMatchEmbed(region, args.String("match id"))
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lolmatchhandler:\n%v\n", err)
+    }
+}
+
+func lolstatushandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    embed := LeagueData.GetStatusEmbed(LeagueData.Region(ctx.GuildID, args.String("region")))
+    _, err := ctx.ReplyEmbed(embed)
     if err != nil {
         log.Printf("Error in lolstatushandler:\n%v\n", err)
     }
 }
 
-func bossnasshandler(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    guild, err := s.State.Guild(msg.GuildID)
+func lolregionhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    _, err := ctx.Reply(fmt.Sprintf("League commands here use %v unless you give them a region.", LeagueData.Region(ctx.GuildID, "")))
+    if err != nil {
+        log.Printf("Error in lolregionhandler:\n%v\n", err)
+    }
+}
+
+func lolregionsethandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var reply string
+    region := FindLeagueRegion(args.String("region"))
+    err := UpdateGuildSettings(ctx.GuildID, func(gs *GuildSettings) {
+        gs.LeagueRegion = region.Name
+    })
+    if err != nil {
+        log.Printf("Error in lolregionsethandler:\n%v\n", err)
+        reply = "Something went wrong, please try again later. Sorry! :("
+    } else {
+        reply = fmt.Sprintf("League commands here will now use %v by default.", region)
+    }
+
+    _, err = ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in lolregionsethandler:\n%v\n", err)
+    }
+}
+
+func lolannouncehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var reply string
+    channel := args.String("channel")
+    if channel == "" {
+        if c := GetGuildSettings(ctx.GuildID).LeagueAnnounceChannel; c != "" {
+            reply = fmt.Sprintf("Rank changes are posted in <#%v>.", c)
+        } else {
+            reply = "Rank changes aren't posted anywhere here."
+        }
+    } else if err := checkAnnounceChannel(s, ctx.GuildID, channel); err != nil {
+        reply = err.Error()
+    } else {
+        err := UpdateGuildSettings(ctx.GuildID, func(gs *GuildSettings) {
+            gs.LeagueAnnounceChannel = channel
+        })
+        if err != nil {
+            log.Printf("Error in lolannouncehandler:\n%v\n", err)
+            reply = "Something went wrong, please try again later. Sorry! :("
+        } else {
+            reply = fmt.Sprintf("Rank changes of people who've used `lol link` here will be posted in <#%v>.", channel)
+        }
+    }
+
+    _, err := ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in lolannouncehandler:\n%v\n", err)
+    }
+}
+
+func lolannounceoffhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    reply := "Rank changes won't be posted here anymore."
+    err := UpdateGuildSettings(ctx.GuildID, func(gs *GuildSettings) {
+        gs.LeagueAnnounceChannel = ""
+    })
+    if err != nil {
+        log.Printf("Error in lolannounceoffhandler:\n%v\n", err)
+        reply = "Something went wrong, please try again later. Sorry! :("
+    }
+
+    _, err = ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in lolannounceoffhandler:\n%v\n", err)
+    }
+}
+
+func lollinkhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var embed *discordgo.MessageEmbed
+    link := GetLeagueLink(ctx.Author.ID)
+    if args.Has("riot id") {
+        region := LeagueData.Region(ctx.GuildID, args.String("region"))
+        var newlink *LeagueLink
+        embed, newlink = LeagueData.LinkEmbed(region, args.String("riot id"))
+        if newlink != nil && link != nil {
+            newlink.Guilds = link.Guilds
+        }
+        link = newlink
+    } else if link != nil {
+        // already linked; this just adds them to this guild's leaderboards
+        embed = &discordgo.MessageEmbed{
+            Color: 0xD13739,
+            Description: fmt.Sprintf("You're linked to **%v**.", link.RiotID),
+        }
+    } else {
+        embed = ctx.Command.UsageEmbed(ctx, &ArgError{ Reason: "You haven't linked an account yet, so give me your Riot ID." })
+    }
+
+    if link != nil {
+        if ctx.GuildID != "" && !link.InGuild(ctx.GuildID) {
+            link.Guilds = append(link.Guilds, ctx.GuildID)
+        }
+        err := UpdateUserData(ctx.Author.ID, func(ud *UserData) {
+            ud.League = link
+        })
+        if err != nil {
+            log.Printf("Error in lollinkhandler:\n%v\n", err)
+            embed = MakeErrorEmbed("Something went wrong, please try again later. Sorry! :(")
+        } else {
+            go func(userID string, link *LeagueLink) {
+                err := LeagueStatsData.Refresh(userID, link)
+                if err != nil {
+                    log.Printf("Error in lollinkhandler:\n%v\n", err)
+                }
+            }(ctx.Author.ID, link)
+        }
+    }
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lollinkhandler:\n%v\n", err)
+    }
+}
+
+func lolunlinkhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var reply string
+    link := GetLeagueLink(ctx.Author.ID)
+    if link == nil {
+        reply = "You haven't linked a League account."
+    } else {
+        err := UpdateUserData(ctx.Author.ID, func(ud *UserData) {
+            ud.League = nil
+        })
+        if err != nil {
+            log.Printf("Error in lolunlinkhandler:\n%v\n", err)
+            reply = "Something went wrong, please try again later. Sorry! :("
+        } else {
+            LeagueStatsData.Forget(ctx.Author.ID)
+            reply = fmt.Sprintf("Unlinked **%v** from your Discord account.", link.RiotID)
+        }
+    }
+
+    _, err := ctx.Reply(reply)
+    if err != nil {
+        log.Printf("Error in lolunlinkhandler:\n%v\n", err)
+    }
+}
+
+func lolleaderboardhandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    var embed *discordgo.MessageEmbed
+    board := strings.ToLower(args.String("board"))
+    champname := args.String("champion")
+    // "lol leaderboard ahri" works like "lol leaderboard champion ahri"
+    if board != "" && board != BoardMastery && board != BoardRank && board != BoardChampion {
+        board, champname = BoardChampion, args.Raw
+    }
+    switch board {
+        case "", BoardMastery:
+            embed = LeagueStatsData.LeaderboardEmbed(ctx.GuildID, BoardMastery, nil)
+        case BoardRank:
+            embed = LeagueStatsData.LeaderboardEmbed(ctx.GuildID, BoardRank, nil)
+        case BoardChampion:
+            if champname == "" {
+                embed = ctx.Command.UsageEmbed(ctx, &ArgError{ Reason: "Which champion?" })
+                break
+            }
+            champ, suggestions := LeagueData.ResolveChampion(champname)
+            if champ == nil {
+                embed = championNotFoundEmbed(champname, suggestions)
+            } else {
+                embed = LeagueStatsData.LeaderboardEmbed(ctx.GuildID, BoardChampion, champ)
+            }
+    }
+
+    _, err := ctx.ReplyEmbed(embed)
+    if err != nil {
+        log.Printf("Error in lolleaderboardhandler:\n%v\n", err)
+    }
+}
+
+func lolcachehandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    switch strings.ToLower(args.String("action")) {
+        case "":
+            embed := LeagueData.Client.Cache.StatsEmbed()
+            embed.Color = s.State.UserColor(s.State.User.ID, ctx.ChannelID)
+            _, err := ctx.ReplyEmbed(embed)
+            if err != nil {
+                log.Printf("Error in lolcachehandler:\n%v\n", err)
+            }
+        case "flush", "clear":
+            n := LeagueData.Client.Cache.Flush()
+            _, err := ctx.Reply(fmt.Sprintf("Flushed %v cached League responses.", n))
+            if err != nil {
+                log.Printf("Error in lolcachehandler:\n%v\n", err)
+            }
+        default:
+            _, err := ctx.ReplyEmbed(ctx.Command.UsageEmbed(ctx, &ArgError{ Reason: "The only action is `flush`." }))
+            if err != nil {
+                log.Printf("Error in lolcachehandler:\n%v\n", err)
+            }
+    }
+}
+
+func bossnasshandler(ctx *CommandContext, s *discordgo.Session, args *CommandArgs) {
+    guild, err := s.State.Guild(ctx.GuildID)
     if err != nil {
         log.Printf("Error in bossnasshandler:\n%v\n", err)
         return
     }
 
     for _, vs := range guild.VoiceStates {
-        if vs.UserID == msg.Author.ID {
+        if vs.UserID == ctx.Author.ID {
             // TODO play sound here
             if !hasbossnass {
-                _, err = s.ChannelMessageSend(msg.ChannelID, "No boss nass :(")
+                _, err = ctx.Reply("No boss nass :(")
                 if err != nil {
                     log.Printf("Error in bossnasshandler:\n%v\n", err)
                     return
                 }
             } else {
-                vc, err := s.ChannelVoiceJoin(msg.GuildID, vs.ChannelID, false, false)
+                vc, err := s.ChannelVoiceJoin(ctx.GuildID, vs.ChannelID, false, false)
                 if err != nil {
                     log.Printf("Error in bossnasshandler:\n%v\n", err)
                     return
@@ -5,6 +5,7 @@ import (
     "github.com/bwmarrin/discordgo"
     "fmt"
     "strings"
+    "time"
 )
 
 func (carg CommandArg) String() string {
@@ -15,15 +16,25 @@ func (carg CommandArg) String() string {
     }
 }
 
-func (cmd *Command) Handle(msg *discordgo.MessageCreate, s *discordgo.Session) {
-    if cmd.AdminOnly && (!Config.IsAdmin(msg.Author.ID) && msg.Author.ID != Config.ControllerID ) {
-        return
-    }
+// content is the message with its prefix already stripped, and prefix is how the user should be told to type commands
+func (cmd *Command) Handle(msg *discordgo.MessageCreate, s *discordgo.Session, content, prefix string) {
+    ctx := NewMessageContext(msg, s)
+    ctx.Prefix = prefix
+    ctx.Command = cmd
+
+    loc := cmd.Pattern.FindStringIndex(content)
+    ctx.argtext = content[loc[1]:]
+
+    cmd.Pipeline()(ctx)
+}
 
-    if cmd.NoTyping == false {
-        s.ChannelTyping(msg.ChannelID)
+// built from the same Args as the help embeds, so the two never disagree
+func (cmd *Command) UsageEmbed(ctx *CommandContext, err error) *discordgo.MessageEmbed {
+    embed := MakeErrorEmbed(fmt.Sprintf("%v\nUsage: `%v%v`", err, ctx.Prefix, cmd.Usage()))
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: fmt.Sprintf("For more info, use %vhelp %v", ctx.Prefix, cmd.Name),
     }
-    cmd.Handler(msg, s)
+    return embed
 }
 
 var CommandCategories = map[string]*struct{
@@ -44,6 +55,12 @@ var CommandCategories = map[string]*struct{
     },
 }
 
+// shared by the league commands, since they all spend the same Riot API quota
+var LeagueCooldowns = []Cooldown {
+    { Bucket: "lol", Scope: ScopeUser, Uses: 3, Per: 30 * time.Second },
+    { Bucket: "lol", Scope: ScopeGuild, Uses: 15, Per: time.Minute },
+}
+
 // go iterates over maps (using range()) in a random order, so this is used to combat that
 var CmdCatOrder = []string{ "fun", "text", "lol", "util" }
 
@@ -55,13 +72,15 @@ var Commands = []Command {
             {
                 Title: "message",
                 Required: true,
+                Type: ArgRest,
             },
         },
+        Summary: "Replaces every vowel in `message` with 'oodle' or 'OODLE'.",
         Description: "Replaces every vowel in `message` with 'oodle' or 'OODLE', depending on whether or not it's a capital.",
         Examples: []string{
             "`c oodle I am a bot.` returns \"OODLE oodlem oodle boodlet.\"",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+oodle\s+.*[aeiou].*`),
+        Pattern: regexp.MustCompile(`(?i)^oodle(\s+|$)`),
         Category: "text",
         Handler: oodlehandler,
     },
@@ -71,13 +90,15 @@ var Commands = []Command {
             {
                 Title: "message",
                 Required: true,
+                Type: ArgRest,
             },
         },
+        Summary: "Works the same as `oodle`, but responds with a TTS message.",
         Description: "Works the same as `oodle`, but responds with a TTS message. Requires the user to have permission to use TTS.",
         Examples: []string{
             "`c oodletts I am a bot.` returns \"OODLE oodlem oodle boodlet.\"",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+oodletts\s+.*[aeiou].*`),
+        Pattern: regexp.MustCompile(`(?i)^oodletts(\s+|$)`),
         Category: "text",
         Handler: oodlettshandler,
     },
@@ -87,6 +108,7 @@ var Commands = []Command {
             {
                 Title: "message",
                 Required: true,
+                Type: ArgRest,
             },
         },
         Description: "Converts as much of `message` as possible into block letters using emoji.",
@@ -96,7 +118,7 @@ var Commands = []Command {
         Aliases: []string {
             "bl",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+bl(ockletters)?\s+\S+`),
+        Pattern: regexp.MustCompile(`(?i)^bl(ockletters)?(\s+|$)`),
         Category: "text",
         Handler: blocklettershandler,
     },
@@ -108,6 +130,7 @@ var Commands = []Command {
             {
                 Title: "number",
                 Required: false,
+                Type: ArgInt,
             },
         },
         Description: "Gets either the most recent xkcd or the xkcd with the given `number`.",
@@ -115,8 +138,11 @@ var Commands = []Command {
             "`c xkcd` embeds the most recent xkcd.",
             "`c xkcd 327` embeds the Little Bobby Tables xkcd.",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+xkcd(\s+\d+)?`),
+        Pattern: regexp.MustCompile(`(?i)^xkcd(\s+|$)`),
         Category: "fun",
+        Cooldowns: []Cooldown {
+            { Scope: ScopeUser, Uses: 5, Per: 30 * time.Second },
+        },
         Handler: xkcdhandler,
     },
     {
@@ -128,7 +154,7 @@ var Commands = []Command {
         Aliases: []string {
             "cf",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+(coinflip|cf)`),
+        Pattern: regexp.MustCompile(`(?i)^(coinflip|cf)(\s+|$)`),
         Category: "fun",
         Handler: coinfliphandler,
     },
@@ -136,22 +162,26 @@ var Commands = []Command {
         Name: "roll",
         Args: []CommandArg {
             {
-                Title: "count",
-                Required: false,
-            },
-            {
-                Title: "sides",
+                Title: "dice",
                 Required: false,
+                Type: ArgRest,
             },
         },
-        Description: "Rolls some dice. If no arguments are supplied, a standard 6-sided die is rolled. If there's only one argument, it's `sides`. If there are two, the number of dice comes first, then the number of sides. These can also be separated by a 'd', like `2d10`, and if only sides is specified, it can be `d10`.",
+        Summary: "Rolls some dice using dice notation, like `2d6+3`.",
+        Description: "Rolls some dice using dice notation. If `dice` isn't supplied, a standard 6-sided die is rolled. A single number is the number of sides, and `NdM` rolls N dice with M sides. Dice can be added to each other and to numbers, and grouped with parentheses.\n" +
+            "After the dice, `khN`/`klN` keeps the highest/lowest N, `dhN`/`dlN` drops them, `!` explodes dice that land on their highest side, and `rN` (or `r<N`, `r>N`) rerolls those values once. " +
+            "`d%` is a percentile die, `dF` is a fudge die, and `adv`/`dis` roll a d20 with advantage/disadvantage. Anything after the dice is a label.",
         Examples: []string{
             "`c roll` returns 1-6",
             "`c roll 20` returns 1-20",
-            "`c roll d10` returns 1-10",
-            "`c roll 2d20` returns 1-20 for two dice",
+            "`c roll 2d6+3` adds 3 to two 6-sided dice",
+            "`c roll 1d20+1d4-2 to hit` rolls a labeled attack",
+            "`c roll 4d6kh3` keeps the highest 3 of four dice",
+            "`c roll 3d6!r1` explodes sixes and rerolls ones",
+            "`c roll adv+5` rolls with advantage",
+            "`c roll 4dF` rolls four fudge dice",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+roll(\s+(\d+)?(\s*d\s*|\s+)?\d+)?`),
+        Pattern: regexp.MustCompile(`(?i)^roll(\s+|$)`),
         Category: "fun",
         Handler: rollhandler,
     },
@@ -159,7 +189,8 @@ var Commands = []Command {
     /* League Commands */
     {
         Name: "lol profile",
-        Description: "Looks up a League of Legends summoner.",
+        Summary: "Looks up a League of Legends summoner by Riot ID, or someone's linked account.",
+        Description: "Looks up a League of Legends summoner by Riot ID, like `Some Name#TAG`. Without a tag, the region's default tag (like NA1) is used. Leave it out to look up your own account from `lol link`, or mention someone to look up theirs.",
         Category: "lol",
         Aliases: []string {
             "lol p",
@@ -169,20 +200,30 @@ var Commands = []Command {
             "l p",
         },
         Args: []CommandArg {
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
             {
                 Title: "summoner",
-                Required: true,
+                Required: false,
+                Type: ArgRest,
             },
         },
         Examples: []string {
-            "`c lol profile miyari` returns Miyari's summoner profile.",
+            "`c lol profile miyari#NA1` returns Miyari's summoner profile.",
+            "`c lol profile euw miyari` looks for miyari#EUW on EUW instead of this server's default region.",
+            "`c lol profile @friend` looks up the account @friend linked.",
         },
-        Pattern: regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+p(rofile)?\s+\S+`),
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+p(rofile)?(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
         Handler: lolprofilehandler,
     },
     {
         Name: "lol mastery",
-        Description: "Looks up a summoner's top champions by mastery, or their mastery level for a specific champion. If the summoner's name contains spaces, you must remove them, unlike with `lol profile`.",
+        Summary: "Looks up a summoner's top champions by mastery, or their mastery on one champion.",
+        Description: "Looks up a summoner's top champions by mastery, or their mastery level for a specific champion. Riot IDs like `Some Name#TAG` can have spaces; names without a tag need quotes if they do. Leave the summoner out to use your linked account, or mention someone to use theirs.",
         Category: "lol",
         Aliases: []string {
             "lol m",
@@ -192,25 +233,131 @@ var Commands = []Command {
             "league m",
         },
         Args: []CommandArg {
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
             {
                 Title: "summoner",
-                Required: true,
+                Required: false,
+                Type: ArgSummoner,
             },
             {
                 Title: "champion",
                 Required: false,
+                Type: ArgRest,
             },
         },
         Examples: []string {
             "`c lol mastery miyari` will get Miyari's top 3 champions.",
-            "`c lol mastery thetinycactus aatrox` will get the tiny cactus's mastery level on Aatrox.",
+            "`c lol mastery the tiny cactus#NA1 aatrox` will get the tiny cactus's mastery level on Aatrox.",
+            "`c lol mastery kr faker` will get Faker's top champions on KR.",
+            "`c lol mastery ahri` will get your linked account's mastery level on Ahri.",
         },
-        Pattern: regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+m(astery)?\s+\S+`),
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+m(astery)?(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
         Handler: lolmasteryhandler,
     },
+    {
+        Name: "lol matches",
+        Summary: "Shows a summoner's most recent games.",
+        Description: fmt.Sprintf("Shows a summoner's most recent games: %v by default, and up to %v. Riot IDs like `Some Name#TAG` can have spaces; names without a tag need quotes if they do. Leave the summoner out to use your linked account, or mention someone to use theirs.", DefaultMatchCount, MaxMatchCount),
+        Category: "lol",
+        Aliases: []string {
+            "lol history",
+            "l matches",
+            "league matches",
+        },
+        Args: []CommandArg {
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
+            {
+                Title: "summoner",
+                Required: false,
+                Type: ArgSummoner,
+            },
+            {
+                Title: "count",
+                Required: false,
+                Type: ArgInt,
+            },
+        },
+        Examples: []string {
+            "`c lol matches miyari` shows Miyari's last 5 games.",
+            "`c lol matches the tiny cactus#NA1 10` shows the tiny cactus's last 10 games.",
+            "`c lol matches 10` shows your linked account's last 10 games.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+(matches|history)(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lolmatcheshandler,
+    },
+    {
+        Name: "lol match",
+        Summary: "Shows every player's stats, items and runes in one game.",
+        Description: "Shows everything about one game: each player's champion, KDA, CS, gold, damage, vision, items and runes, and each team's objectives. Match IDs are shown by `lol matches`.",
+        Category: "lol",
+        Aliases: []string {
+            "l match",
+            "league match",
+        },
+        Args: []CommandArg {
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
+            {
+                Title: "match id",
+                Required: true,
+            },
+        },
+        Examples: []string {
+            "`c lol match NA1_4567890123` shows that game.",
+            "`c lol match euw 6543210987` shows game 6543210987 on EUW.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+match(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lolmatchhandler,
+    },
+    {
+        Name: "lol live",
+        Summary: "Shows who's in a summoner's current game, with their champions, spells and ranks.",
+        Description: "Shows who's in a summoner's current game, with their champions, summoner spells and ranks. Leave the summoner out to use your linked account, or mention someone to use theirs.",
+        Category: "lol",
+        Aliases: []string {
+            "lol l",
+            "lol game",
+            "l live",
+            "l l",
+            "league live",
+        },
+        Args: []CommandArg {
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
+            {
+                Title: "summoner",
+                Required: false,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c lol live miyari` shows Miyari's current game.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+(l(ive)?|game)(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lollivehandler,
+    },
     {
         Name: "lol champ",
-        Description: "Gets details on a specific champion.",
+        Summary: "Gets details on a champion, including their stats and how much they grow each level.",
+        Description: fmt.Sprintf("Gets details on a specific champion, including their base stats and how much they grow each level. Give a level from 1 to %v (at the end, in text commands) to see their stats at that level instead.", MaxChampionLevel),
         Category: "lol",
         Aliases: []string {
             "lol champion",
@@ -226,14 +373,106 @@ var Commands = []Command {
             {
                 Title: "champion",
                 Required: true,
+                Type: ArgRest,
+            },
+            {
+                Title: "level",
+                Required: false,
+                Type: ArgInt,
             },
         },
         Examples: []string {
             "`c lol c aatrox` will return details about Aatrox",
+            "`c lol c mundo` also works with nicknames and the start of a name",
+            "`c lol c miss fortune 11` shows Miss Fortune's stats at level 11",
         },
-        Pattern: regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+c(hamp(ion)?)?\s+\S+`),
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+c(hamp(ion)?)?(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
         Handler: lolchamphandler,
     },
+    {
+        Name: "lol compare",
+        Summary: "Compares two champions' stats side by side at a level.",
+        Description: fmt.Sprintf("Compares two champions' stats side by side at a level (1 by default, up to %v), along with their attack, defense, magic and difficulty ratings. Put champion names with spaces in quotes.", MaxChampionLevel),
+        Category: "lol",
+        Aliases: []string {
+            "lol vs",
+            "l compare",
+            "l vs",
+            "league compare",
+        },
+        Args: []CommandArg {
+            {
+                Title: "first champion",
+                Required: true,
+            },
+            {
+                Title: "second champion",
+                Required: true,
+            },
+            {
+                Title: "level",
+                Required: false,
+                Type: ArgInt,
+            },
+        },
+        Examples: []string {
+            "`c lol compare darius garen` compares Darius and Garen at level 1.",
+            "`c lol compare \"miss fortune\" jinx 18` compares Miss Fortune and Jinx at level 18.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+(compare|vs)(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lolcomparehandler,
+    },
+    {
+        Name: "lol item",
+        Description: "Gets an item's cost, stats, description and what it builds from and into.",
+        Category: "lol",
+        Aliases: []string {
+            "lol i",
+            "l item",
+            "l i",
+            "league item",
+            "league i",
+        },
+        Args: []CommandArg {
+            {
+                Title: "item",
+                Required: true,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c lol item infinity edge` will return details about Infinity Edge",
+            "`c lol i bf sword` also works with part of a name",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+i(tem)?(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lolitemhandler,
+    },
+    {
+        Name: "lol rune",
+        Description: "Gets a rune's description, tree and row, or lists the runes in a tree.",
+        Category: "lol",
+        Aliases: []string {
+            "l rune",
+            "league rune",
+        },
+        Args: []CommandArg {
+            {
+                Title: "rune",
+                Required: true,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c lol rune electrocute` will return details about Electrocute",
+            "`c lol rune precision` lists the runes in the Precision tree",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+rune(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lolrunehandler,
+    },
     {
         Name: "lol status",
         Description: "Gets League of Legends service statuses.",
@@ -245,15 +484,188 @@ var Commands = []Command {
             "league status",
             "league s",
         },
-        Pattern: regexp.MustCompile(`(?i)^c\s+l(ol|eague)?\s+s(tatus)?`),
+        Args: []CommandArg {
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
+        },
+        Examples: []string {
+            "`c lol status` shows the statuses for this server's default region.",
+            "`c lol status euw` shows the statuses for EUW.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+s(tatus)?(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
         Handler: lolstatushandler,
     },
+    {
+        Name: "lol region set",
+        Summary: "Changes the default region League commands use in this server.",
+        Description: "Changes the region League commands use in this server when they aren't given one. Only server managers can change it. The regions are: " + strings.Join(LeagueRegionNames(), ", ") + ".",
+        Category: "lol",
+        Aliases: []string {
+            "l region set",
+            "league region set",
+        },
+        Args: []CommandArg {
+            {
+                Title: "region",
+                Required: true,
+                Type: ArgRegion,
+            },
+        },
+        Examples: []string {
+            "`c lol region set euw` makes EUW the default.",
+        },
+        GuildOnly: true,
+        Permissions: discordgo.PermissionManageServer,
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+region\s+set(\s+|$)`),
+        Handler: lolregionsethandler,
+    },
+    {
+        Name: "lol region",
+        Description: "Shows the region League commands use in this server when they aren't given one. Server managers can change it with `lol region set`.",
+        Category: "lol",
+        Aliases: []string {
+            "l region",
+            "league region",
+        },
+        Examples: []string {
+            "`c lol region` shows the current default region.",
+        },
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+region(\s+|$)`),
+        Handler: lolregionhandler,
+    },
+    {
+        Name: "lol announce off",
+        Description: "Stops posting League rank changes in this server.",
+        Category: "lol",
+        Aliases: []string {
+            "l announce off",
+            "league announce off",
+        },
+        Examples: []string {
+            "`c lol announce off` stops posting rank changes.",
+        },
+        GuildOnly: true,
+        Permissions: discordgo.PermissionManageServer,
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+announce\s+off(\s+|$)`),
+        Handler: lolannounceoffhandler,
+    },
+    {
+        Name: "lol announce",
+        Summary: "Shows or changes the channel where linked members' rank changes are announced.",
+        Description: "Shows or changes the channel where the bot posts when someone who's linked their League account here climbs or drops a division. Only server managers can use it, and `lol announce off` turns it off.",
+        Category: "lol",
+        Aliases: []string {
+            "l announce",
+            "league announce",
+        },
+        Args: []CommandArg {
+            {
+                Title: "channel",
+                Required: false,
+                Type: ArgChannel,
+            },
+        },
+        Examples: []string {
+            "`c lol announce` shows where rank changes are posted.",
+            "`c lol announce #league` posts them in #league.",
+        },
+        GuildOnly: true,
+        Permissions: discordgo.PermissionManageServer,
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+announce(\s+|$)`),
+        Handler: lolannouncehandler,
+    },
+    {
+        Name: "lol link",
+        Summary: "Links your League account, so League commands and leaderboards can use it.",
+        Description: "Links your League account to your Discord account, so League commands look it up when you don't give them a summoner, and others can look it up by mentioning you. It also puts you on the `lol leaderboard` of the server you link it in; use it without a Riot ID to join another server's leaderboards.",
+        Category: "lol",
+        Aliases: []string {
+            "l link",
+            "league link",
+        },
+        Args: []CommandArg {
+            {
+                Title: "riot id",
+                Required: false,
+                Type: ArgSummoner,
+            },
+            {
+                Title: "region",
+                Required: false,
+                Type: ArgRegion,
+            },
+        },
+        Examples: []string {
+            "`c lol link the tiny cactus#NA1` links the tiny cactus on this server's default region.",
+            "`c lol link miyari#EUW euw` links Miyari on EUW.",
+            "`c lol link` adds your linked account to this server's leaderboards.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+link(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lollinkhandler,
+    },
+    {
+        Name: "lol leaderboard",
+        Summary: "Ranks this server's linked League accounts by mastery, solo/duo rank, or one champion.",
+        Description: "Ranks everyone in this server who's linked their League account by total mastery points, solo/duo rank, or mastery points on one champion. Stats are updated every hour.",
+        Category: "lol",
+        Aliases: []string {
+            "lol lb",
+            "lol top",
+            "l leaderboard",
+            "l lb",
+            "league leaderboard",
+        },
+        Args: []CommandArg {
+            {
+                Title: "board",
+                Required: false,
+            },
+            {
+                Title: "champion",
+                Required: false,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c lol leaderboard` ranks everyone by total mastery points.",
+            "`c lol leaderboard rank` ranks everyone by solo/duo rank.",
+            "`c lol leaderboard champion ahri` ranks everyone by mastery points on Ahri.",
+        },
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+(leaderboard|lb|top)(\s+|$)`),
+        Cooldowns: LeagueCooldowns,
+        Handler: lolleaderboardhandler,
+    },
+    {
+        Name: "lol unlink",
+        Description: "Forgets the League account you linked with `lol link`.",
+        Category: "lol",
+        Aliases: []string {
+            "l unlink",
+            "league unlink",
+        },
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+unlink(\s+|$)`),
+        Handler: lolunlinkhandler,
+    },
 
     /* Util Commands */
     {
         Name: "help",
         Description: "Displays this help message.",
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+help.*`),
+        Args: []CommandArg {
+            {
+                Title: "command",
+                Required: false,
+                Type: ArgRest,
+            },
+        },
+        Pattern: regexp.MustCompile(`(?i)^help(\s+|$)`),
         Handler: helphandler,
     },
     {
@@ -264,11 +676,200 @@ var Commands = []Command {
             "git",
             "repo",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+(source|src|git|repo)`),
+        Pattern: regexp.MustCompile(`(?i)^(source|src|git|repo)(\s+|$)`),
         Category: "util",
         Handler: srchandler,
     },
 
+    {
+        Name: "dad set",
+        Summary: "Changes dad mode for this server.",
+        Description: "Changes dad mode, where I reply to messages like \"I'm hungry\" with \"Hi hungry, I'm cactusbot!\" The settings are `on`, `off`, `chance` and `cooldown`. Only server managers can change them.",
+        Args: []CommandArg {
+            {
+                Title: "setting",
+                Required: true,
+            },
+            {
+                Title: "value",
+                Required: false,
+            },
+        },
+        Examples: []string {
+            "`c dad set on` turns dad mode on for the whole server, and `c dad set off` turns it off.",
+            "`c dad set off here` turns it off just for the current channel.",
+            "`c dad set chance 25` makes me reply to 25% of messages.",
+            "`c dad set cooldown 60` makes me wait at least 60 seconds between replies in a channel.",
+        },
+        GuildOnly: true,
+        Permissions: discordgo.PermissionManageServer,
+        Pattern: regexp.MustCompile(`(?i)^dad\s+set(\s+|$)`),
+        Category: "fun",
+        Handler: dadsethandler,
+    },
+    {
+        Name: "dad optout",
+        Description: "Stops me from replying to your messages in dad mode, in every server.",
+        Examples: []string {
+            "`c dad optout` stops me from replying to you.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^dad\s+optout(\s+|$)`),
+        Category: "fun",
+        Handler: dadopthandler,
+    },
+    {
+        Name: "dad optin",
+        Description: "Lets me reply to your messages in dad mode again, after `dad optout`.",
+        Examples: []string {
+            "`c dad optin` undoes `c dad optout`.",
+        },
+        Pattern: regexp.MustCompile(`(?i)^dad\s+optin(\s+|$)`),
+        Category: "fun",
+        Handler: dadopthandler,
+    },
+    {
+        Name: "dad status",
+        Summary: "Shows the dad mode settings for this server.",
+        Description: "Shows the settings for dad mode, where I reply to messages like \"I'm hungry\" with \"Hi hungry, I'm cactusbot!\" Server managers can change them with `dad set`, and anyone can opt out with `dad optout`.",
+        Examples: []string {
+            "`c dad status` (or just `c dad`) shows the current settings.",
+        },
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^dad(\s+status)?(\s+|$)`),
+        Category: "fun",
+        Handler: dadstatushandler,
+    },
+    {
+        Name: "prefix set",
+        Summary: "Changes the command prefix for this server.",
+        Description: "Changes the command prefix for this server. Once it's changed, the default prefixes stop working here, but mentioning me always works. Only server managers can change it.",
+        Args: []CommandArg {
+            {
+                Title: "new prefix",
+                Required: true,
+            },
+        },
+        Examples: []string {
+            "`c prefix set !` changes the prefix, so commands look like `!roll`.",
+        },
+        GuildOnly: true,
+        Permissions: discordgo.PermissionManageServer,
+        Pattern: regexp.MustCompile(`(?i)^prefix\s+set(\s+|$)`),
+        Category: "util",
+        Handler: prefixsethandler,
+    },
+    {
+        Name: "prefix reset",
+        Description: "Goes back to the default command prefixes in this server. Only server managers can change it.",
+        Examples: []string {
+            "`c prefix reset` goes back to the default prefixes.",
+        },
+        GuildOnly: true,
+        Permissions: discordgo.PermissionManageServer,
+        Pattern: regexp.MustCompile(`(?i)^prefix\s+reset(\s+|$)`),
+        Category: "util",
+        Handler: prefixresethandler,
+    },
+    {
+        Name: "prefix show",
+        Description: "Shows the command prefix for this server.",
+        Examples: []string {
+            "`c prefix show` (or just `c prefix`) shows the current prefix.",
+        },
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^prefix(\s+show)?(\s+|$)`),
+        Category: "util",
+        Handler: prefixhandler,
+    },
+
+    {
+        Name: "perms list",
+        Summary: "Lists the roles that are allowed or denied for commands in this server.",
+        Description: "Lists the roles that are allowed or denied for a command in this server, or for every command that has any.",
+        Args: []CommandArg {
+            {
+                Title: "command",
+                Required: false,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c perms list` shows every command with role rules.",
+            "`c perms list echo` shows the role rules for `echo`.",
+        },
+        Permissions: discordgo.PermissionManageServer,
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^perms\s+list(\s+|$)`),
+        Category: "util",
+        Handler: permslisthandler,
+    },
+    {
+        Name: "perms allow",
+        Summary: "Lets a role use a command in this server, even without the permissions it needs.",
+        Description: "Lets a role use a command in this server, even if they don't have the Discord permissions it normally needs. Once a command has allowed roles, only those roles (and server managers) can use it.",
+        Args: []CommandArg {
+            {
+                Title: "role",
+                Required: true,
+                Type: ArgRole,
+            },
+            {
+                Title: "command",
+                Required: true,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c perms allow @Moderators echo` lets Moderators use `echo`.",
+        },
+        Permissions: discordgo.PermissionManageServer,
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^perms\s+allow(\s+|$)`),
+        Handler: permssethandler,
+    },
+    {
+        Name: "perms deny",
+        Summary: "Stops a role from using a command in this server.",
+        Description: "Stops a role from using a command in this server. Denied roles win over allowed ones.",
+        Args: []CommandArg {
+            {
+                Title: "role",
+                Required: true,
+                Type: ArgRole,
+            },
+            {
+                Title: "command",
+                Required: true,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c perms deny @Muted oodletts` stops Muted from using `oodletts`.",
+        },
+        Permissions: discordgo.PermissionManageServer,
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^perms\s+deny(\s+|$)`),
+        Handler: permssethandler,
+    },
+    {
+        Name: "perms reset",
+        Description: "Removes every allowed and denied role for a command in this server.",
+        Args: []CommandArg {
+            {
+                Title: "command",
+                Required: true,
+                Type: ArgRest,
+            },
+        },
+        Examples: []string {
+            "`c perms reset echo` goes back to the default permissions for `echo`.",
+        },
+        Permissions: discordgo.PermissionManageServer,
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^perms\s+reset(\s+|$)`),
+        Handler: permsresethandler,
+    },
+
     /* Admin Commands */
     {
         Name: "shutdown",
@@ -278,30 +879,67 @@ var Commands = []Command {
         },
         AdminOnly: true,
         NoTyping: true,
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+(shutdown|sd)`),
+        Pattern: regexp.MustCompile(`(?i)^(shutdown|sd)(\s+|$)`),
         Handler: shutdownhandler,
     },
     {
         Name: "sowner",
         Description: "Returns the user who owns the server.",
-        AdminOnly: true,
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+sowner`),
+        Permissions: discordgo.PermissionManageServer,
+        GuildOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^sowner(\s+|$)`),
         Handler: sownerhandler,
     },
     {
         Name: "echo",
         Description: "Echos back what you say.",
-        AdminOnly: true,
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+echo\s+\S+`),
+        Args: []CommandArg {
+            {
+                Title: "message",
+                Required: true,
+                Type: ArgRest,
+            },
+        },
+        Permissions: discordgo.PermissionManageMessages,
+        Pattern: regexp.MustCompile(`(?i)^echo(\s+|$)`),
         Handler: echohandler,
     },
     {
         Name: "bossnass",
         Description: "Does a Boss Nass impression.",
         NoTyping: true,
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+bossnass`),
+        GuildOnly: true,
+        Cooldowns: []Cooldown {
+            { Scope: ScopeGuild, Uses: 1, Per: 30 * time.Second },
+        },
+        Pattern: regexp.MustCompile(`(?i)^bossnass(\s+|$)`),
         Handler: bossnasshandler,
     },
+    {
+        Name: "metrics",
+        Description: "Shows how often each command has been used since the bot started.",
+        AdminOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^metrics(\s+|$)`),
+        Handler: metricshandler,
+    },
+    {
+        Name: "lol cache",
+        Description: "Shows how the League API cache is doing, or flushes it.",
+        Args: []CommandArg {
+            {
+                Title: "action",
+                Required: false,
+            },
+        },
+        Examples: []string {
+            "`c lol cache` shows how many responses are cached and the hit rate.",
+            "`c lol cache flush` forgets everything in the cache.",
+        },
+        AdminOnly: true,
+        Pattern: regexp.MustCompile(`(?i)^l(ol|eague)?\s+cache(\s+|$)`),
+        Handler: lolcachehandler,
+        Category: "lol",
+    },
     {
         Name: "invite",
         Description: "Creates a discord invite link to add this bot to another server.",
@@ -309,7 +947,7 @@ var Commands = []Command {
         Aliases: []string {
             "inv",
         },
-        Pattern: regexp.MustCompile(`(?i)^c(actus)?\s+inv(ite)?`),
+        Pattern: regexp.MustCompile(`(?i)^inv(ite)?(\s+|$)`),
         Handler: invitehandler,
     },
 }
@@ -324,7 +962,7 @@ func InitHelpEmbed(embed *discordgo.MessageEmbed) {
 
     // prepare a help embed to reduce CPU load later on
     embed.Title = "Command List"
-    embed.Description = "You should begin each command with `cactus` or simply `c`.\nFor example: `cactus help` or `c help`.\nFor info about a particular command, use `c help [command]`."
+    embed.Description = HelpDescription("")
 
     for _, catname := range(CmdCatOrder) {
         if !EnableLOL && catname == "lol" {
@@ -350,17 +988,24 @@ func InitHelpEmbed(embed *discordgo.MessageEmbed) {
     }
 }
 
+// prefix is the guild's own prefix, or "" for the defaults
+func HelpDescription(prefix string) string {
+    var desc string
+    if prefix == "" {
+        desc = fmt.Sprintf("You should begin each command with %[1]v.\nFor example: `%[2]vhelp`.\nFor info about a particular command, use `%[2]vhelp [command]`.", DefaultPrefixList("or"), GuildDisplayPrefix(""))
+    } else {
+        desc = fmt.Sprintf("In this server, you should begin each command with `%[1]v`.\nFor example: `%[1]vhelp`.\nFor info about a particular command, use `%[1]vhelp [command]`.", prefix)
+    }
+    return desc + "\nYou can also mention me instead of using a prefix, or use commands as slash commands, like `/roll` or `/lol profile`."
+}
+
 func InitCommandEmbeds(m map[string]*discordgo.MessageEmbed) {
     for _, cmd := range(Commands) {
         if !EnableLOL && cmd.Category == "lol" {
             continue
         }
         m[cmd.Name] = &discordgo.MessageEmbed{}
-        m[cmd.Name].Title = "`" + cmd.Name
-        for _, arg := range(cmd.Args) {
-            m[cmd.Name].Title += fmt.Sprintf(" %s", arg)
-        }
-        m[cmd.Name].Title += "`"
+        m[cmd.Name].Title = "`" + cmd.Usage() + "`"
 
         if cmd.Args != nil {
             m[cmd.Name].Footer = &discordgo.MessageEmbedFooter{
@@ -378,6 +1023,35 @@ func InitCommandEmbeds(m map[string]*discordgo.MessageEmbed) {
             })
         }
 
+        if cmd.SkipsRegion() {
+            m[cmd.Name].Fields = append(m[cmd.Name].Fields, &discordgo.MessageEmbedField{
+                Name: "Region",
+                Value: "The region can be left out. If the first word isn't a region, it's read as the next argument instead, " +
+                    "so write `region:euw` when a summoner's name could be mistaken for one.",
+                Inline: false,
+            })
+        }
+
+        if cmd.Permissions != 0 {
+            m[cmd.Name].Fields = append(m[cmd.Name].Fields, &discordgo.MessageEmbedField{
+                Name: "Requires",
+                Value: PermissionString(cmd.Permissions),
+                Inline: false,
+            })
+        }
+
+        if cmd.Cooldowns != nil {
+            var limits []string
+            for _, c := range(cmd.Cooldowns) {
+                limits = append(limits, c.String())
+            }
+            m[cmd.Name].Fields = append(m[cmd.Name].Fields, &discordgo.MessageEmbedField{
+                Name: "Cooldown",
+                Value: strings.Join(limits, "\n"),
+                Inline: false,
+            })
+        }
+
         if cmd.Aliases != nil {
             m[cmd.Name].Fields = append(m[cmd.Name].Fields, &discordgo.MessageEmbedField{
                 Name: "Aliases",
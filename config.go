@@ -17,6 +17,8 @@ type Configuration struct {
     LogWebhookID    string      `json:",omitempty"`
     LogWebhookToken string      `json:",omitempty"`
     LeagueToken     string      `json:",omitempty"`
+    Prefixes        []string    `json:",omitempty"` // defaults to "cactus" and "c"
+    LeagueCacheFile string      `json:",omitempty"` // if set, the League API cache is saved here
 }
 
 func LoadConfig() Configuration {
@@ -0,0 +1,198 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "log"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+func NewMessageContext(msg *discordgo.MessageCreate, s *discordgo.Session) *CommandContext {
+    return &CommandContext{
+        Session: s,
+        Message: msg,
+        Author: msg.Author,
+        ChannelID: msg.ChannelID,
+        GuildID: msg.GuildID,
+        Prefix: GuildDisplayPrefix(msg.GuildID),
+    }
+}
+
+func NewInteractionContext(i *discordgo.InteractionCreate, s *discordgo.Session) *CommandContext {
+    ctx := &CommandContext{
+        Session: s,
+        Interaction: i,
+        ChannelID: i.ChannelID,
+        GuildID: i.GuildID,
+        Prefix: "/",
+    }
+    // Member is only filled in for guilds, User only for DMs
+    if i.Member != nil {
+        ctx.Author = i.Member.User
+    } else {
+        ctx.Author = i.User
+    }
+    return ctx
+}
+
+func (ctx *CommandContext) IsInteraction() bool {
+    return ctx.Interaction != nil
+}
+
+// the author's permissions in the channel the command was used in
+func (ctx *CommandContext) AuthorPermissions() (int64, error) {
+    if ctx.IsInteraction() {
+        if ctx.Interaction.Member == nil {
+            return 0, errors.New("no permissions outside of a guild")
+        }
+        return ctx.Interaction.Member.Permissions, nil
+    }
+    // the message carries the author's roles, so this works even if the member isn't in the state
+    return ctx.Session.State.MessagePermissions(ctx.Message.Message)
+}
+
+func (ctx *CommandContext) Reply(content string) (*discordgo.Message, error) {
+    return ctx.send(&discordgo.MessageSend{
+        Content: content,
+    })
+}
+
+// like Reply, but nobody in content gets pinged, @everyone included; for repeating what users typed
+func (ctx *CommandContext) ReplyNoMentions(content string) (*discordgo.Message, error) {
+    return ctx.send(&discordgo.MessageSend{
+        Content: content,
+        AllowedMentions: &discordgo.MessageAllowedMentions{},
+    })
+}
+
+func (ctx *CommandContext) ReplyTTS(content string) (*discordgo.Message, error) {
+    return ctx.send(&discordgo.MessageSend{
+        Content: content,
+        TTS: true,
+    })
+}
+
+func (ctx *CommandContext) ReplyEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+    return ctx.send(&discordgo.MessageSend{
+        Embeds: []*discordgo.MessageEmbed{ embed },
+    })
+}
+
+// text commands get a normal channel message. slash commands get an immediate response if they
+// haven't been deferred, fill in the deferred response if they have, and send followups after that.
+func (ctx *CommandContext) send(data *discordgo.MessageSend) (*discordgo.Message, error) {
+    if !ctx.IsInteraction() {
+        return ctx.Session.ChannelMessageSendComplex(ctx.ChannelID, data)
+    }
+
+    if !ctx.acked {
+        ctx.acked = true
+        ctx.replied = true
+        err := ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+            Type: discordgo.InteractionResponseChannelMessageWithSource,
+            Data: &discordgo.InteractionResponseData{
+                Content: data.Content,
+                TTS: data.TTS,
+                Embeds: data.Embeds,
+                AllowedMentions: data.AllowedMentions,
+            },
+        })
+        if err != nil {
+            return nil, err
+        }
+        return ctx.Session.InteractionResponse(ctx.Interaction.Interaction)
+    }
+
+    if !ctx.replied {
+        ctx.replied = true
+        return ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, &discordgo.WebhookEdit{
+            Content: &data.Content,
+            Embeds: &data.Embeds,
+            AllowedMentions: data.AllowedMentions,
+        })
+    }
+
+    return ctx.Session.FollowupMessageCreate(ctx.Interaction.Interaction, true, &discordgo.WebhookParams{
+        Content: data.Content,
+        TTS: data.TTS,
+        Embeds: data.Embeds,
+        AllowedMentions: data.AllowedMentions,
+    })
+}
+
+// shows the bot as "typing" for text commands. slash commands are deferred instead, which looks the same
+func (ctx *CommandContext) Typing() {
+    if !ctx.IsInteraction() {
+        ctx.Session.ChannelTyping(ctx.ChannelID)
+        return
+    }
+
+    if ctx.acked {
+        return
+    }
+    ctx.acked = true
+    err := ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+        Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+    })
+    if err != nil {
+        log.Printf("Error in CommandContext.Typing:\n%v\n", err)
+    }
+}
+
+// only the person who used the command can see this. falls back to a normal reply for text commands
+func (ctx *CommandContext) ReplyPrivate(content string) error {
+    if !ctx.IsInteraction() {
+        _, err := ctx.Reply(content)
+        return err
+    }
+
+    if ctx.acked {
+        ctx.replied = true
+        _, err := ctx.Session.FollowupMessageCreate(ctx.Interaction.Interaction, true, &discordgo.WebhookParams{
+            Content: content,
+            Flags: discordgo.MessageFlagsEphemeral,
+        })
+        return err
+    }
+
+    ctx.acked = true
+    ctx.replied = true
+    return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+        Type: discordgo.InteractionResponseChannelMessageWithSource,
+        Data: &discordgo.InteractionResponseData{
+            Content: content,
+            Flags: discordgo.MessageFlagsEphemeral,
+        },
+    })
+}
+
+// slash commands that never replied would otherwise be left "thinking" forever, or fail outright
+func (ctx *CommandContext) finish() {
+    if !ctx.IsInteraction() || ctx.replied {
+        return
+    }
+
+    var err error
+    if ctx.acked {
+        err = ctx.Session.InteractionResponseDelete(ctx.Interaction.Interaction)
+    } else {
+        err = ctx.ReplyPrivate("Done!")
+    }
+    if err != nil {
+        log.Printf("Error in CommandContext.finish:\n%v\n", err)
+    }
+}
+
+// what the user actually sent, for logs and error reports
+func (ctx *CommandContext) Trigger() string {
+    if !ctx.IsInteraction() {
+        return ctx.Message.Content
+    }
+    parts := []string{ "/" + ctx.Command.Name }
+    for _, opt := range(ctx.options) {
+        parts = append(parts, fmt.Sprintf("%v:%v", opt.Name, opt.Value))
+    }
+    return strings.Join(parts, " ")
+}
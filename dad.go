@@ -0,0 +1,146 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "regexp"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    DefaultDadChance = 50 // percent
+    DefaultDadCooldown = 5 * 60 // seconds
+    MaxDadCooldown = 24 * 60 * 60 // seconds
+)
+
+var DadMatcher = regexp.MustCompile(`(?i)^i(['’]?m|\s+am)\s+\S`)
+var DadReplacer = regexp.MustCompile(`(?i)^i(['’]?m|\s+am)\s+`)
+var DadSanitizer = regexp.MustCompile(`(?i)@+(everyone|here)`)
+
+// last time a dad joke was made in each channel
+var dadLastJoke = make(map[string]time.Time)
+var dadLock sync.Mutex
+
+// per-guild dad mode settings; a nil pointer means the default
+type DadSettings struct {
+    Enabled     bool            `json:",omitempty"`
+    Channels    map[string]bool `json:",omitempty"` // overrides Enabled for individual channels
+    Chance      *int            `json:",omitempty"` // percent chance that a matching message gets a reply
+    Cooldown    *int            `json:",omitempty"` // seconds between jokes in the same channel
+}
+
+func (ds *DadSettings) EnabledIn(channelID string) bool {
+    if enabled, ok := ds.Channels[channelID]; ok {
+        return enabled
+    }
+    return ds.Enabled
+}
+
+func (ds *DadSettings) GetChance() int {
+    if ds.Chance == nil {
+        return DefaultDadChance
+    }
+    return *ds.Chance
+}
+
+func (ds *DadSettings) GetCooldown() time.Duration {
+    if ds.Cooldown == nil {
+        return DefaultDadCooldown * time.Second
+    }
+    return time.Duration(*ds.Cooldown) * time.Second
+}
+
+// returns true if the cooldown had passed, in which case it starts over
+func dadCooldownReady(channelID string, cooldown time.Duration) bool {
+    dadLock.Lock()
+    defer dadLock.Unlock()
+    if time.Since(dadLastJoke[channelID]) < cooldown {
+        return false
+    }
+    // channels that are past the longest cooldown there is don't need remembering
+    for c, t := range(dadLastJoke) {
+        if time.Since(t) >= MaxDadCooldown * time.Second {
+            delete(dadLastJoke, c)
+        }
+    }
+    dadLastJoke[channelID] = time.Now()
+    return true
+}
+
+// called for every message that isn't a command
+func DadJoke(s *discordgo.Session, m *discordgo.MessageCreate) {
+    if m.GuildID == "" || !DadMatcher.MatchString(m.Content) {
+        return
+    }
+
+    ds := GetGuildSettings(m.GuildID).Dad
+    if !ds.EnabledIn(m.ChannelID) || GetUserData(m.Author.ID).DadOptOut {
+        return
+    }
+
+    // roll before touching the cooldown, so a miss doesn't use it up
+    if randIntn(100) >= ds.GetChance() || !dadCooldownReady(m.ChannelID, ds.GetCooldown()) {
+        return
+    }
+
+    response := DadReplacer.ReplaceAllString(m.Content, "")
+    response = DadSanitizer.ReplaceAllString(response, "$1")
+    _, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+        Content: fmt.Sprintf("Hi %s, I'm cactusbot!", response),
+        AllowedMentions: &discordgo.MessageAllowedMentions{}, // "I'm <@someone>" shouldn't ping them
+    })
+    if err != nil {
+        log.Printf("Error in DadJoke:\n%v\n", err)
+    }
+}
+
+func (ds *DadSettings) StatusEmbed(channelID string) *discordgo.MessageEmbed {
+    onoff := func(b bool) string {
+        if b {
+            return "On"
+        }
+        return "Off"
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title: "Dad Mode",
+        Fields: []*discordgo.MessageEmbedField{
+            {
+                Name: "Server",
+                Value: onoff(ds.Enabled),
+                Inline: true,
+            },
+            {
+                Name: "This Channel",
+                Value: onoff(ds.EnabledIn(channelID)),
+                Inline: true,
+            },
+            {
+                Name: "Chance",
+                Value: fmt.Sprintf("%v%%", ds.GetChance()),
+                Inline: true,
+            },
+            {
+                Name: "Cooldown",
+                Value: ds.GetCooldown().String(),
+                Inline: true,
+            },
+        },
+    }
+
+    var overrides string
+    for ch, enabled := range(ds.Channels) {
+        overrides += fmt.Sprintf("<#%v>: %v\n", ch, onoff(enabled))
+    }
+    if overrides != "" {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: "Channel Overrides",
+            Value: overrides,
+        })
+    }
+
+    return embed
+}
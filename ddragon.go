@@ -0,0 +1,158 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "os"
+)
+
+const (
+    // takes a version
+    ITEM_DATA = "http://ddragon.leagueoflegends.com/cdn/%v/data/en_US/item.json"
+    // takes a version
+    RUNE_DATA = "http://ddragon.leagueoflegends.com/cdn/%v/data/en_US/runesReforged.json"
+    // takes a version
+    SUMMONER_SPELL_DATA = "http://ddragon.leagueoflegends.com/cdn/%v/data/en_US/summoner.json"
+    // takes a rune's icon path
+    RUNE_ICON = "https://ddragon.leagueoflegends.com/cdn/img/%v"
+)
+
+// the data dragon files besides championFull.json, saved next to it and updated along with it.
+// load decodes the file into the helper.
+var ddragonFiles = []struct{
+    File    string
+    URL     string
+    load    func(helper *LeagueHelper, data []byte) error
+}{
+    { "item.json", ITEM_DATA, func(helper *LeagueHelper, data []byte) error {
+        items := &ItemFile{}
+        err := json.Unmarshal(data, items)
+        if err == nil {
+            helper.ItemData = items
+        }
+        return err
+    } },
+    { "runesReforged.json", RUNE_DATA, func(helper *LeagueHelper, data []byte) error {
+        var trees []*RuneTreeDTO
+        err := json.Unmarshal(data, &trees)
+        if err == nil {
+            helper.RuneTrees = trees
+        }
+        return err
+    } },
+    { "summoner.json", SUMMONER_SPELL_DATA, func(helper *LeagueHelper, data []byte) error {
+        spells := &SummonerSpellFile{}
+        err := json.Unmarshal(data, spells)
+        if err == nil {
+            helper.SummonerSpellData = spells
+        }
+        return err
+    } },
+}
+
+// the version in a data dragon file, or "" if it doesn't say (like runesReforged.json)
+func dataFileVersion(file string) string {
+    data, err := ioutil.ReadFile(file)
+    if err != nil {
+        return ""
+    }
+    var v struct{
+        Version string `json:"version"`
+    }
+    if json.Unmarshal(data, &v) != nil {
+        return ""
+    }
+    return v.Version
+}
+
+func downloadDataFile(url, file string) error {
+    resp, err := http.Get(url)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("%v returned %v", url, resp.Status)
+    }
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(file, body, 0644)
+}
+
+// loads the other data dragon files, downloading any that are missing for the given version.
+// they're all downloaded again if download is true, or if any of them are from another version,
+// so files that don't say their version stay in sync with the ones that do.
+// these are nice to have, so failures are only logged. the helper should be locked, or not in use yet.
+func (helper *LeagueHelper) updateExtraData(version string, download bool) {
+    for _, f := range(ddragonFiles) {
+        if v := dataFileVersion(f.File); v != "" && v != version {
+            log.Printf("%v is from version %v; getting %v...\n", f.File, v, version)
+            download = true
+        }
+    }
+
+    for _, f := range(ddragonFiles) {
+        if _, err := os.Stat(f.File); download || os.IsNotExist(err) {
+            err = downloadDataFile(fmt.Sprintf(f.URL, version), f.File)
+            if err != nil {
+                log.Printf("Error downloading %v:\n%v\n", f.File, err)
+                continue
+            }
+        }
+
+        data, err := ioutil.ReadFile(f.File)
+        if err == nil {
+            err = f.load(helper, data)
+        }
+        if err != nil {
+            log.Printf("Error loading %v:\n%v\nPlease delete the file and run the bot again.\n", f.File, err)
+        }
+    }
+}
+
+// returns "" if not found
+func (helper *LeagueHelper) getItemName(id int) string {
+    if helper.ItemData == nil {
+        return ""
+    }
+    if item, ok := helper.ItemData.Data[fmt.Sprint(id)]; ok {
+        return item.Name
+    }
+    return ""
+}
+
+// returns "" if not found
+func (helper *LeagueHelper) getSummonerSpellName(id int) string {
+    if helper.SummonerSpellData == nil {
+        return ""
+    }
+    for _, spell := range(helper.SummonerSpellData.Data) {
+        if spell.Key == fmt.Sprint(id) {
+            return spell.Name
+        }
+    }
+    return ""
+}
+
+// works for both runes and rune trees (styles); returns "" if not found
+func (helper *LeagueHelper) getRuneName(id int) string {
+    for _, tree := range(helper.RuneTrees) {
+        if tree.ID == id {
+            return tree.Name
+        }
+        for _, slot := range(tree.Slots) {
+            for _, r := range(slot.Runes) {
+                if r.ID == id {
+                    return r.Name
+                }
+            }
+        }
+    }
+    return ""
+}
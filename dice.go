@@ -0,0 +1,549 @@
+package main
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// limits, so nobody can make the bot roll forever or send a novel
+const (
+    MaxDiceCount    = 100   // dice in one term, like the 100 in 100d6
+    MaxDiceSides    = 1000
+    MaxDiceRolls    = 250   // every die rolled in an expression, including explosions and rerolls
+    MaxDiceNumber   = 1000000
+    MaxDiceTotal    = 1000000000 // for anything an expression works out along the way
+    MaxDiceLabel    = 100
+)
+
+// the old `c roll 20` and `c roll 2 20` forms
+var (
+    legacySides = regexp.MustCompile(`^\s*(\d+)\s*$`)
+    legacyCount = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s*$`)
+)
+
+// a parsed dice expression, like 4d6kh3+2. rolling it gives the total and a description of
+// every die that was rolled.
+type DiceExpr interface {
+    roll(dr *diceRoller) (int, string, error)
+}
+
+// keeps track of how many dice an expression has rolled so far
+type diceRoller struct {
+    rolled  int
+}
+
+func (dr *diceRoller) die(sides int) (int, error) {
+    dr.rolled++
+    if dr.rolled > MaxDiceRolls {
+        return 0, fmt.Errorf("that's more than %v dice", MaxDiceRolls)
+    }
+    return randIntn(sides) + 1, nil
+}
+
+type numberNode struct {
+    n   int
+}
+
+func (n *numberNode) roll(dr *diceRoller) (int, string, error) {
+    return n.n, strconv.Itoa(n.n), nil
+}
+
+type negateNode struct {
+    x   DiceExpr
+}
+
+func (n *negateNode) roll(dr *diceRoller) (int, string, error) {
+    v, s, err := n.x.roll(dr)
+    return -v, "-" + s, err
+}
+
+type groupNode struct {
+    x   DiceExpr
+}
+
+func (n *groupNode) roll(dr *diceRoller) (int, string, error) {
+    v, s, err := n.x.roll(dr)
+    return v, "(" + s + ")", err
+}
+
+type binaryNode struct {
+    op      byte
+    l, r    DiceExpr
+}
+
+// shown instead of * and /, which discord would treat as markdown
+var opSymbols = map[byte]string {
+    '+': "+",
+    '-': "-",
+    '*': "×",
+    '/': "÷",
+}
+
+func (n *binaryNode) roll(dr *diceRoller) (int, string, error) {
+    lv, ls, err := n.l.roll(dr)
+    if err != nil {
+        return 0, "", err
+    }
+    rv, rs, err := n.r.roll(dr)
+    if err != nil {
+        return 0, "", err
+    }
+
+    var v int
+    switch n.op {
+        case '+':
+            v = lv + rv
+        case '-':
+            v = lv - rv
+        case '*':
+            // both sides are within MaxDiceTotal, so this can't overflow an int64
+            if p := int64(lv) * int64(rv); p > MaxDiceTotal || p < -MaxDiceTotal {
+                v = MaxDiceTotal + 1
+            } else {
+                v = int(p)
+            }
+        case '/':
+            if rv == 0 {
+                return 0, "", fmt.Errorf("can't divide by zero")
+            }
+            v = lv / rv
+    }
+    if v > MaxDiceTotal || v < -MaxDiceTotal {
+        return 0, "", fmt.Errorf("that adds up to more than %v", MaxDiceTotal)
+    }
+    return v, fmt.Sprintf("%v %v %v", ls, opSymbols[n.op], rs), nil
+}
+
+// which dice keepdrop applies to
+const (
+    keepHighest = "kh"
+    keepLowest  = "kl"
+    dropHighest = "dh"
+    dropLowest  = "dl"
+)
+
+// one NdM term and its modifiers
+type diceNode struct {
+    src         string  // how it was written, e.g. 4d6kh3
+    count       int
+    sides       int
+    fudge       bool    // sides are -1, 0 and +1
+    explode     bool    // roll another die whenever one lands on its highest side
+    reroll      string  // "", "=", "<" or ">"; values matching it are rerolled once
+    rerollOn    int
+    keepdrop    string
+    keepdropN   int
+}
+
+type rolledDie struct {
+    value       int
+    rerolled    []int   // what it showed before being rerolled
+    exploded    bool
+    dropped     bool
+}
+
+func (n *diceNode) rerolls(v int) bool {
+    switch n.reroll {
+        case "=":
+            return v == n.rerollOn
+        case "<":
+            return v <= n.rerollOn
+        case ">":
+            return v >= n.rerollOn
+    }
+    return false
+}
+
+func (n *diceNode) rollOne(dr *diceRoller) (int, error) {
+    v, err := dr.die(n.sides)
+    if n.fudge {
+        v -= 2
+    }
+    return v, err
+}
+
+func (n *diceNode) roll(dr *diceRoller) (int, string, error) {
+    var dice []*rolledDie
+    for i := 0; i < n.count; i++ {
+        d := &rolledDie{}
+        v, err := n.rollOne(dr)
+        if err != nil {
+            return 0, "", err
+        }
+        // rerolls only happen once, so they always finish
+        if n.rerolls(v) {
+            d.rerolled = append(d.rerolled, v)
+            v, err = n.rollOne(dr)
+            if err != nil {
+                return 0, "", err
+            }
+        }
+        d.value = v
+        dice = append(dice, d)
+
+        for n.explode && d.value == n.sides {
+            d.exploded = true
+            v, err := n.rollOne(dr)
+            if err != nil {
+                return 0, "", err
+            }
+            d = &rolledDie{ value: v }
+            dice = append(dice, d)
+        }
+    }
+
+    if n.keepdrop != "" {
+        // sort a copy by value so the originals stay in the order they were rolled
+        order := make([]*rolledDie, len(dice))
+        copy(order, dice)
+        for i := 1; i < len(order); i++ {
+            for j := i; j > 0 && order[j].value < order[j-1].value; j-- {
+                order[j], order[j-1] = order[j-1], order[j]
+            }
+        }
+        k := n.keepdropN
+        if k > len(order) {
+            k = len(order)
+        }
+        switch n.keepdrop {
+            case keepHighest:
+                order = order[:len(order)-k]
+            case keepLowest:
+                order = order[k:]
+            case dropHighest:
+                order = order[len(order)-k:]
+            case dropLowest:
+                order = order[:k]
+        }
+        for _, d := range(order) {
+            d.dropped = true
+        }
+    }
+
+    total := 0
+    shown := make([]string, len(dice))
+    for i, d := range(dice) {
+        if !d.dropped {
+            total += d.value
+        }
+        s := ""
+        for _, r := range(d.rerolled) {
+            s += n.face(r) + "→"
+        }
+        s += n.face(d.value)
+        if d.exploded {
+            s += "!"
+        }
+        if d.dropped {
+            s = "~~" + s + "~~"
+        }
+        shown[i] = s
+    }
+    return total, fmt.Sprintf("%v [%v]", n.src, strings.Join(shown, ", ")), nil
+}
+
+func (n *diceNode) face(v int) string {
+    if !n.fudge {
+        return strconv.Itoa(v)
+    }
+    switch v {
+        case 1:
+            return "+"
+        case -1:
+            return "-"
+    }
+    return "0"
+}
+
+/* Parsing */
+
+// a recursive descent parser for:
+//   expr    = term { ("+" | "-") term }
+//   term    = unary { ("*" | "/") unary }
+//   unary   = "-" unary | primary
+//   primary = number | dice | "adv" | "dis" | "(" expr ")"
+//   dice    = [number] "d" (number | "%" | "F") { modifier }
+// anything after a complete expression is its label
+type diceParser struct {
+    s   string
+    pos int
+}
+
+func (p *diceParser) errorf(format string, a ...interface{}) error {
+    return fmt.Errorf("at character %v: %v", p.pos + 1, fmt.Sprintf(format, a...))
+}
+
+func (p *diceParser) skipSpace() {
+    for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+        p.pos++
+    }
+}
+
+func (p *diceParser) peek() byte {
+    if p.pos >= len(p.s) {
+        return 0
+    }
+    return p.s[p.pos]
+}
+
+// true (and skips it) if the input continues with word, case-insensitively, and word isn't
+// just the start of a longer word
+func (p *diceParser) keyword(word string) bool {
+    end := p.pos + len(word)
+    if end > len(p.s) || !strings.EqualFold(p.s[p.pos:end], word) {
+        return false
+    }
+    if r, _ := utf8.DecodeRuneInString(p.s[end:]); unicode.IsLetter(r) {
+        return false
+    }
+    p.pos = end
+    return true
+}
+
+// -1 if there isn't a number here
+func (p *diceParser) number() (int, error) {
+    start := p.pos
+    for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+        p.pos++
+    }
+    if p.pos == start {
+        return -1, nil
+    }
+    n, err := strconv.Atoi(p.s[start:p.pos])
+    if err != nil || n > MaxDiceNumber {
+        p.pos = start
+        return 0, p.errorf("numbers can't be bigger than %v", MaxDiceNumber)
+    }
+    return n, nil
+}
+
+func (p *diceParser) expr() (DiceExpr, error) {
+    left, err := p.term()
+    if err != nil {
+        return nil, err
+    }
+    for {
+        p.skipSpace()
+        op := p.peek()
+        if op != '+' && op != '-' {
+            return left, nil
+        }
+        p.pos++
+        right, err := p.term()
+        if err != nil {
+            return nil, err
+        }
+        left = &binaryNode{ op: op, l: left, r: right }
+    }
+}
+
+func (p *diceParser) term() (DiceExpr, error) {
+    left, err := p.unary()
+    if err != nil {
+        return nil, err
+    }
+    for {
+        p.skipSpace()
+        op := p.peek()
+        if op != '*' && op != '/' {
+            return left, nil
+        }
+        p.pos++
+        right, err := p.unary()
+        if err != nil {
+            return nil, err
+        }
+        left = &binaryNode{ op: op, l: left, r: right }
+    }
+}
+
+func (p *diceParser) unary() (DiceExpr, error) {
+    p.skipSpace()
+    if p.peek() == '-' {
+        p.pos++
+        x, err := p.unary()
+        if err != nil {
+            return nil, err
+        }
+        return &negateNode{ x: x }, nil
+    }
+    return p.primary()
+}
+
+func (p *diceParser) primary() (DiceExpr, error) {
+    p.skipSpace()
+    start := p.pos
+
+    if p.peek() == '(' {
+        p.pos++
+        x, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        p.skipSpace()
+        if p.peek() != ')' {
+            return nil, p.errorf("missing a `)`")
+        }
+        p.pos++
+        return &groupNode{ x: x }, nil
+    }
+
+    if p.keyword("advantage") || p.keyword("adv") {
+        return &diceNode{ src: "adv", count: 2, sides: 20, keepdrop: keepHighest, keepdropN: 1 }, nil
+    }
+    if p.keyword("disadvantage") || p.keyword("dis") {
+        return &diceNode{ src: "dis", count: 2, sides: 20, keepdrop: keepLowest, keepdropN: 1 }, nil
+    }
+
+    count, err := p.number()
+    if err != nil {
+        return nil, err
+    }
+    if p.peek() != 'd' && p.peek() != 'D' {
+        if count < 0 {
+            if p.pos >= len(p.s) {
+                return nil, p.errorf("expected a number or dice at the end")
+            }
+            return nil, p.errorf("expected a number or dice, not `%c`", p.peek())
+        }
+        return &numberNode{ n: count }, nil
+    }
+    p.pos++
+    if count < 0 {
+        count = 1
+    }
+
+    n := &diceNode{ count: count }
+    switch p.peek() {
+        case '%':
+            p.pos++
+            n.sides = 100
+        case 'f', 'F':
+            p.pos++
+            n.fudge = true
+            n.sides = 3
+        default:
+            n.sides, err = p.number()
+            if err != nil {
+                return nil, err
+            }
+            if n.sides < 0 {
+                return nil, p.errorf("expected the number of sides after `d`")
+            }
+    }
+
+    if n.count < 1 || n.count > MaxDiceCount {
+        return nil, p.errorf("you can roll between 1 and %v dice at once", MaxDiceCount)
+    }
+    if n.sides < 1 || n.sides > MaxDiceSides {
+        return nil, p.errorf("dice can have between 1 and %v sides", MaxDiceSides)
+    }
+
+    err = p.modifiers(n)
+    if err != nil {
+        return nil, err
+    }
+    n.src = p.s[start:p.pos]
+    return n, nil
+}
+
+// modifiers come right after the dice, with no spaces, e.g. 4d6kh3 or 3d6!r1
+func (p *diceParser) modifiers(n *diceNode) error {
+    for p.pos < len(p.s) {
+        rest := strings.ToLower(p.s[p.pos:])
+        switch {
+            case strings.HasPrefix(rest, "!"):
+                p.pos++
+                if n.fudge || n.sides == 1 {
+                    return p.errorf("those dice can't explode, they'd never stop")
+                }
+                n.explode = true
+            case strings.HasPrefix(rest, "r"):
+                p.pos++
+                n.reroll = "="
+                if c := p.peek(); c == '<' || c == '>' {
+                    n.reroll = string(c)
+                    p.pos++
+                }
+                v, err := p.number()
+                if err != nil {
+                    return err
+                }
+                if v < 0 {
+                    return p.errorf("expected which number to reroll after `r`")
+                }
+                n.rerollOn = v
+            case strings.HasPrefix(rest, "kh"), strings.HasPrefix(rest, "kl"),
+                strings.HasPrefix(rest, "dh"), strings.HasPrefix(rest, "dl"),
+                strings.HasPrefix(rest, "k"):
+                if n.keepdrop != "" {
+                    return p.errorf("dice can only keep or drop once")
+                }
+                n.keepdrop = keepHighest
+                p.pos++
+                if len(rest) > 1 && (rest[1] == 'h' || rest[1] == 'l') {
+                    n.keepdrop = rest[:2]
+                    p.pos++
+                }
+                v, err := p.number()
+                if err != nil {
+                    return err
+                }
+                if v < 0 {
+                    return p.errorf("expected how many dice to keep or drop")
+                }
+                n.keepdropN = v
+            default:
+                return nil
+        }
+    }
+    return nil
+}
+
+// parses an expression with an optional label after it, like "1d20+5 to hit"
+func ParseDice(s string) (DiceExpr, string, error) {
+    if m := legacySides.FindStringSubmatch(s); m != nil {
+        s = "d" + m[1]
+    } else if m := legacyCount.FindStringSubmatch(s); m != nil {
+        s = m[1] + "d" + m[2]
+    }
+
+    p := &diceParser{ s: strings.Join(strings.Fields(s), " ") }
+    x, err := p.expr()
+    if err != nil {
+        return nil, "", err
+    }
+
+    p.skipSpace()
+    label := strings.TrimSpace(strings.TrimLeft(p.s[p.pos:], "#:"))
+    if r, _ := utf8.DecodeRuneInString(p.s[p.pos:]); label != "" && !unicode.IsLetter(r) && r != '#' && r != ':' {
+        return nil, "", p.errorf("didn't expect `%c`", r)
+    }
+    // "2d6 d8" is more likely a missing + than a label
+    if word := strings.Fields(p.s[p.pos:]); len(word) > 0 && isDiceWord(word[0]) {
+        return nil, "", p.errorf("expected `+` or `-` before `%v`", word[0])
+    }
+    // nobody gets pinged by a dice roll
+    label = strings.ReplaceAll(label, "@", "")
+    if r := []rune(label); len(r) > MaxDiceLabel {
+        label = string(r[:MaxDiceLabel])
+    }
+    return x, label, nil
+}
+
+// true for words like d8 or 2d6kh1, but not adv and dis
+func isDiceWord(word string) bool {
+    p := &diceParser{ s: word }
+    x, err := p.primary()
+    n, ok := x.(*diceNode)
+    return err == nil && ok && p.pos == len(word) && n.src != "adv" && n.src != "dis"
+}
+
+// rolls the expression, returning the total and every individual roll
+func RollDice(x DiceExpr) (int, string, error) {
+    return x.roll(&diceRoller{})
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestParseDiceModifiers(t *testing.T) {
+    tests := []struct{
+        in          string
+        count       int
+        sides       int
+        explode     bool
+        reroll      string
+        rerollOn    int
+        keepdrop    string
+        keepdropN   int
+    }{
+        { "4d6", 4, 6, false, "", 0, "", 0 },
+        { "d20", 1, 20, false, "", 0, "", 0 },
+        { "4d6kh3", 4, 6, false, "", 0, keepHighest, 3 },
+        { "4d6k3", 4, 6, false, "", 0, keepHighest, 3 },
+        { "2d20kl1", 2, 20, false, "", 0, keepLowest, 1 },
+        { "5d10dh2", 5, 10, false, "", 0, dropHighest, 2 },
+        { "4D6DL1", 4, 6, false, "", 0, dropLowest, 1 },
+        { "3d6!", 3, 6, true, "", 0, "", 0 },
+        { "3d6!r1", 3, 6, true, "=", 1, "", 0 },
+        { "2d8r<2", 2, 8, false, "<", 2, "", 0 },
+        { "2d8r>7kh1", 2, 8, false, ">", 7, keepHighest, 1 },
+        { "d%", 1, 100, false, "", 0, "", 0 },
+        { "adv", 2, 20, false, "", 0, keepHighest, 1 },
+        { "dis", 2, 20, false, "", 0, keepLowest, 1 },
+    }
+
+    for _, tt := range(tests) {
+        x, _, err := ParseDice(tt.in)
+        if err != nil {
+            t.Errorf("ParseDice(%q) failed: %v", tt.in, err)
+            continue
+        }
+        n, ok := x.(*diceNode)
+        if !ok {
+            t.Errorf("ParseDice(%q) gave a %T, not dice", tt.in, x)
+            continue
+        }
+        if n.count != tt.count || n.sides != tt.sides || n.explode != tt.explode || n.reroll != tt.reroll ||
+            n.rerollOn != tt.rerollOn || n.keepdrop != tt.keepdrop || n.keepdropN != tt.keepdropN {
+            t.Errorf("ParseDice(%q) = %+v", tt.in, *n)
+        }
+    }
+}
+
+func TestParseDiceErrors(t *testing.T) {
+    tests := []string{
+        "1d1!", // would explode forever
+        "dF!",
+        "4d6k3k1", // keeps twice
+        "4d6kh",
+        "3d6r",
+        "0d6",
+        "101d6",
+        "d1001",
+        "d",
+        "1d6+",
+        "(1d6",
+        "1d6 + * 2",
+        // dice after the expression are a missing +, not a label
+        "2d6 d8",
+        "1d20 4d6kh3 fire",
+    }
+
+    for _, in := range(tests) {
+        if _, _, err := ParseDice(in); err == nil {
+            t.Errorf("ParseDice(%q) should have failed", in)
+        }
+    }
+}
+
+func TestParseDiceLabels(t *testing.T) {
+    // input -> label
+    labels := map[string]string {
+        "1d20+5 to hit": "to hit",
+        "1d20 #stealth": "stealth",
+        "2d6: damage": "damage",
+        "1d20 ping @everyone": "ping everyone",
+        "1d20 épée": "épée",
+        "1d20 dodge": "dodge",
+        "20": "",
+        "2 20": "",
+    }
+    for in, want := range(labels) {
+        _, label, err := ParseDice(in)
+        if err != nil {
+            t.Errorf("ParseDice(%q) failed: %v", in, err)
+        } else if label != want {
+            t.Errorf("ParseDice(%q) label = %q, want %q", in, label, want)
+        }
+    }
+
+    // long labels are cut by character, not byte
+    _, label, err := ParseDice("1d20 " + strings.Repeat("é", MaxDiceLabel + 10))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if label != strings.Repeat("é", MaxDiceLabel) {
+        t.Errorf("long label cut to %q", label)
+    }
+}
+
+func TestRollDiceTooBig(t *testing.T) {
+    for _, in := range([]string{ "1000000*1000000*1000000*1000000", "-1000000*1000000*1000", "1000*1000*1001" }) {
+        x, _, err := ParseDice(in)
+        if err != nil {
+            t.Fatalf("ParseDice(%q) failed: %v", in, err)
+        }
+        if total, _, err := RollDice(x); err == nil {
+            t.Errorf("RollDice(%q) = %v, want an error", in, total)
+        }
+    }
+}
+
+// whatever gets rolled, keeping and dropping leave the right number of dice counted
+func TestRollDiceKeepDrop(t *testing.T) {
+    tests := []struct{
+        in          string
+        dropped     int
+        min, max    int
+    }{
+        { "4d6kh3", 1, 3, 18 },
+        { "4d6kl1", 3, 1, 6 },
+        { "5d10dh2", 2, 3, 30 },
+        { "4d6dl1", 1, 3, 18 },
+        { "2d6k5", 0, 2, 12 },
+        { "2d6dl5", 2, 0, 0 },
+    }
+
+    for _, tt := range(tests) {
+        x, _, err := ParseDice(tt.in)
+        if err != nil {
+            t.Fatalf("ParseDice(%q) failed: %v", tt.in, err)
+        }
+        for i := 0; i < 50; i++ {
+            total, rolls, err := RollDice(x)
+            if err != nil {
+                t.Fatalf("RollDice(%q) failed: %v", tt.in, err)
+            }
+            if dropped := strings.Count(rolls, "~~") / 2; dropped != tt.dropped {
+                t.Errorf("RollDice(%q) dropped %v dice, want %v: %v", tt.in, dropped, tt.dropped, rolls)
+            }
+            if total < tt.min || total > tt.max {
+                t.Errorf("RollDice(%q) = %v, want %v to %v", tt.in, total, tt.min, tt.max)
+            }
+        }
+    }
+}
+
+func TestRollDiceExplode(t *testing.T) {
+    x, _, err := ParseDice("10d2!")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 50; i++ {
+        total, rolls, err := RollDice(x)
+        if err != nil {
+            // enough twos in a row can hit MaxDiceRolls, which is fine
+            continue
+        }
+        // every exploded die adds another one
+        shown := strings.Count(rolls, ",") + 1
+        if exploded := strings.Count(rolls, "!]") + strings.Count(rolls, "!,"); shown != 10 + exploded {
+            t.Errorf("10d2! showed %v dice with %v explosions: %v", shown, exploded, rolls)
+        }
+        if total < 10 {
+            t.Errorf("10d2! = %v, less than the dice rolled", total)
+        }
+    }
+}
@@ -2,4 +2,4 @@ module cactusbot
 
 go 1.15
 
-require github.com/bwmarrin/discordgo v0.22.0
+require github.com/bwmarrin/discordgo v0.27.1
@@ -0,0 +1,170 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// discord won't accept descriptions longer than this
+const SlashDescriptionMax = 100
+
+var slashOptionTypes = map[ArgType]discordgo.ApplicationCommandOptionType{
+    ArgString: discordgo.ApplicationCommandOptionString,
+    ArgInt: discordgo.ApplicationCommandOptionInteger,
+    ArgUser: discordgo.ApplicationCommandOptionUser,
+    ArgChannel: discordgo.ApplicationCommandOptionChannel,
+    ArgRole: discordgo.ApplicationCommandOptionRole,
+    ArgRegion: discordgo.ApplicationCommandOptionString,
+    ArgRest: discordgo.ApplicationCommandOptionString,
+    ArgSummoner: discordgo.ApplicationCommandOptionString,
+}
+
+func slashName(name string) string {
+    return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
+// commands with long descriptions should have a Summary that fits; cutting them off is a last resort
+func slashDescription(cmd *Command) string {
+    desc := cmd.Summary
+    if desc == "" {
+        desc = cmd.Description
+    }
+    if utf8.RuneCountInString(desc) > SlashDescriptionMax {
+        log.Printf("Description for %v is too long for a slash command\n", cmd.Name)
+        return string([]rune(desc)[:SlashDescriptionMax-1]) + "…"
+    }
+    return desc
+}
+
+// discord wants required options before optional ones, even though text commands
+// can have an optional region first
+func (cmd *Command) slashOptions() []*discordgo.ApplicationCommandOption {
+    var required, optional []*discordgo.ApplicationCommandOption
+    for _, arg := range(cmd.Args) {
+        opt := &discordgo.ApplicationCommandOption{
+            Type: slashOptionTypes[arg.Type],
+            Name: slashName(arg.Title),
+            Description: arg.Title,
+            Required: arg.Required,
+        }
+        if arg.Type == ArgRegion {
+            for _, name := range(LeagueRegionNames()) {
+                opt.Choices = append(opt.Choices, &discordgo.ApplicationCommandOptionChoice{
+                    Name: LeagueRegions[name].String(),
+                    Value: name,
+                })
+            }
+        }
+        if arg.Required {
+            required = append(required, opt)
+        } else {
+            optional = append(optional, opt)
+        }
+    }
+    return append(required, optional...)
+}
+
+// commands with two-word names like "lol profile" become subcommands ("/lol profile"), everything else is top-level
+func BuildApplicationCommands() []*discordgo.ApplicationCommand {
+    var appcmds []*discordgo.ApplicationCommand
+    groups := make(map[string]*discordgo.ApplicationCommand)
+    adminperms := int64(0) // nobody but server admins sees these by default
+
+    for i := range(Commands) {
+        cmd := &Commands[i]
+        if !EnableLOL && cmd.Category == "lol" {
+            continue
+        }
+
+        parts := strings.SplitN(cmd.Name, " ", 2)
+        if len(parts) == 1 {
+            appcmd := &discordgo.ApplicationCommand{
+                Name: slashName(cmd.Name),
+                Description: slashDescription(cmd),
+                Options: cmd.slashOptions(),
+            }
+            if cmd.AdminOnly {
+                appcmd.DefaultMemberPermissions = &adminperms
+            }
+            appcmds = append(appcmds, appcmd)
+            continue
+        }
+
+        group, ok := groups[parts[0]]
+        if !ok {
+            group = &discordgo.ApplicationCommand{
+                Name: slashName(parts[0]),
+                Description: fmt.Sprintf("%v commands", parts[0]),
+            }
+            groups[parts[0]] = group
+            appcmds = append(appcmds, group)
+        }
+        group.Options = append(group.Options, &discordgo.ApplicationCommandOption{
+            Type: discordgo.ApplicationCommandOptionSubCommand,
+            Name: slashName(parts[1]),
+            Description: slashDescription(cmd),
+            Options: cmd.slashOptions(),
+        })
+    }
+
+    return appcmds
+}
+
+// replaces whatever slash commands were registered before, so removed commands go away too
+func RegisterSlashCommands(s *discordgo.Session) {
+    _, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", BuildApplicationCommands())
+    if err != nil {
+        log.Printf("Error in RegisterSlashCommands:\n%v\n", err)
+    }
+}
+
+// filled in by IndexCommands. handlers look commands up by name, so FindCommand can't
+// read Commands directly without making Commands refer to itself
+var commandIndex = make(map[string]*Command)
+
+func IndexCommands() {
+    for i := range(Commands) {
+        commandIndex[slashName(Commands[i].Name)] = &Commands[i]
+    }
+}
+
+// returns nil if there's no command with that name
+func FindCommand(name string) *Command {
+    return commandIndex[slashName(strings.TrimSpace(name))]
+}
+
+func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    if i.Type != discordgo.InteractionApplicationCommand {
+        return
+    }
+    defer recoverEvent(s, "interactionCreate", "")
+
+    data := i.ApplicationCommandData()
+    name := data.Name
+    opts := data.Options
+    if len(opts) == 1 && opts[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+        name += " " + opts[0].Name
+        opts = opts[0].Options
+    }
+
+    cmd := FindCommand(name)
+    if cmd == nil {
+        log.Printf("Error in interactionCreate:\nunknown command %v\n", name)
+        return
+    }
+
+    cmd.HandleInteraction(i, s, opts)
+}
+
+func (cmd *Command) HandleInteraction(i *discordgo.InteractionCreate, s *discordgo.Session, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+    ctx := NewInteractionContext(i, s)
+    ctx.Command = cmd
+    ctx.options = opts
+    defer ctx.finish()
+
+    cmd.Pipeline()(ctx)
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+    "context"
     "net/http"
     "net/url"
     "encoding/json"
+    "errors"
     "fmt"
     "io/ioutil"
     "log"
+    "math"
     "strings"
     "strconv"
     "time"
@@ -17,16 +20,31 @@ import (
 )
 
 const (
-    // TODO make this non-NA-specific
-    API_URL = "https://na1.api.riotgames.com"
-    // takes summoner name, URL encoded (obviously)
-    SUMMONER = "/lol/summoner/v4/summoners/by-name/%s"
-    // takes summonerID
-    ALL_CHAMPION_MASTERY = "/lol/champion-mastery/v4/champion-masteries/by-summoner/%v"
+    // requested from a LeagueRegion's AccountHost
+    // takes game name and tag line, URL encoded (obviously)
+    ACCOUNT_BY_RIOT_ID = "/riot/account/v1/accounts/by-riot-id/%s/%s"
+    // takes PUUID
+    ACCOUNT_BY_PUUID = "/riot/account/v1/accounts/by-puuid/%v"
+    // all of these API paths are requested from a LeagueRegion's Platform host
+    // takes PUUID
+    SUMMONER = "/lol/summoner/v4/summoners/by-puuid/%v"
+    // takes PUUID
+    ALL_CHAMPION_MASTERY = "/lol/champion-mastery/v4/champion-masteries/by-puuid/%v"
     // takes championID; tack onto end of ALL_CHAMPION_MASTERY
     BY_CHAMPION = "/by-champion/%v"
-    // takes summonerID
-    MASTERY_SCORE = "/lol/champion-mastery/v4/scores/by-summoner/%v"
+    // takes PUUID
+    MASTERY_SCORE = "/lol/champion-mastery/v4/scores/by-puuid/%v"
+    // takes PUUID
+    LEAGUE_ENTRIES = "/lol/league/v4/entries/by-puuid/%v"
+    // takes PUUID
+    ACTIVE_GAME = "/lol/spectator/v5/active-games/by-summoner/%v"
+    // these two are requested from a LeagueRegion's Regional host instead
+    // takes PUUID and how many to get
+    MATCH_IDS = "/lol/match/v5/matches/by-puuid/%v/ids?start=0&count=%v"
+    // takes match ID, like NA1_1234567890
+    MATCH = "/lol/match/v5/matches/%v"
+    // takes a lowercase tier, like gold
+    RANK_EMBLEM = "https://raw.communitydragon.org/latest/plugins/rcp-fe-lol-static-assets/global/default/images/ranked-emblem/emblem-%v.png"
     // takes a version and profile icon ID
     PROFILE_ICON = "http://ddragon.leagueoflegends.com/cdn/%v/img/profileicon/%v.png"
     // takes version, group, image name
@@ -35,13 +53,13 @@ const (
     VERSIONS_URL = "https://ddragon.leagueoflegends.com/api/versions.json"
     // gets championFull.json
     CHAMPION_FULL = "http://ddragon.leagueoflegends.com/cdn/%v/data/en_US/championFull.json"
-    // gets server status
-    SERVER_STATUS = "/lol/status/v3/shard-data"
+    // gets platform status
+    SERVER_STATUS = "/lol/status/v4/platform-data"
 )
 
 // returns true if all is well; false if not
 func (helper *LeagueHelper) Init(token string) bool {
-    helper.Token = token
+    helper.Client = NewRiotClient(token, NewRiotCache(Config.LeagueCacheFile))
 
     downloaded := false
     if _, err := os.Stat("championFull.json"); os.IsNotExist(err) {
@@ -71,11 +89,14 @@ func (helper *LeagueHelper) Init(token string) bool {
     if !downloaded {
         _, err := helper.UpdateData()
         if err != "" {
-            log.Println("Error in helper.Init:\n%v\n", err)
+            log.Printf("Error in helper.Init:\n%v\n", err)
             return false
         }
     }
 
+    // UpdateData already got these if the version changed
+    helper.updateExtraData(helper.Version, false)
+
     return true
 }
 
@@ -185,15 +206,18 @@ func (helper *LeagueHelper) UpdateData() (bool, string) {
         }
         // set the latest data into the helper
         helper.ChampionData = cfile
+        helper.updateExtraData(latestver, true)
         log.Printf("Successfully updated League data to %v\n", latestver)
         helper.Version = latestver
         return true, ""
     } else {
         log.Printf("League data is up-to-date (version %v)\n", helper.Version)
+        // in case they couldn't be downloaded last time
+        if helper.ItemData == nil || len(helper.RuneTrees) == 0 || helper.SummonerSpellData == nil {
+            helper.updateExtraData(helper.Version, false)
+        }
         return false, ""
     }
-
-    return true, ""
 }
 
 // should only be run in a separate goroutine
@@ -208,24 +232,30 @@ func (i *ImageDTO) GetURL(version string) string {
     return fmt.Sprintf(IMAGE_URL_PATTERN, version, i.Group, i.Full)
 }
 
-func (s *Summoner) GetIconURL(version string) string {
-    return fmt.Sprintf(PROFILE_ICON, version, s.ProfileIconID)
+// like Some Name#TAG
+func (a *RiotAccount) RiotID() string {
+    return a.GameName + "#" + a.TagLine
 }
 
-func sanitizeChampionName(champname string) string {
-    return strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(champname), " ", ""), "'", "")
+// the data dragon version, for image URLs
+func (helper *LeagueHelper) CurrentVersion() string {
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+    return helper.Version
+}
+
+func (s *Summoner) GetIconURL(version string) string {
+    return fmt.Sprintf(PROFILE_ICON, version, s.ProfileIconID)
 }
 
-// returns -1 if not found
+// returns -1 if not found; see ResolveChampion
 func (helper *LeagueHelper) getChampionIDByName(name string) int {
-    sname := sanitizeChampionName(name)
-    for k, v := range(helper.ChampionData.Data) {
-        if k == sname {
-            idval, _ := strconv.Atoi(v.Key)
-            return idval
-        }
+    champ, _ := helper.ResolveChampion(name)
+    if champ == nil {
+        return -1
     }
-    return -1
+    idval, _ := strconv.Atoi(champ.Key)
+    return idval
 }
 
 // returns "" if not found
@@ -246,268 +276,229 @@ func MakeErrorEmbed(err string) *discordgo.MessageEmbed {
     }
 }
 
-func (helper *LeagueHelper) getStatus() (*ServerStatus, string) {
-    requrl := API_URL + SERVER_STATUS + "?api_key=" + helper.Token
+// turns an error from the Riot API into something worth showing people; what is the thing
+// that might not have been found, e.g. "Summoner"
+func riotErrorEmbed(where string, err error, what string) *discordgo.MessageEmbed {
+    switch {
+        case errors.Is(err, ErrRiotNotFound):
+            return MakeErrorEmbed(what + " not found.")
+        case errors.Is(err, ErrRiotRateLimited):
+            return leagueBusyEmbed(err)
+        case errors.Is(err, ErrRiotServer):
+            return MakeErrorEmbed("Riot's servers are having trouble right now. Try again later.")
+        case errors.Is(err, context.DeadlineExceeded):
+            return MakeErrorEmbed("The League API took too long to answer. Try again later.")
+    }
+    // anything else (like an expired key) is our problem, not the user's
+    log.Printf("Error in %v:\n%v\n", where, err)
+    return MakeErrorEmbed("Error retrieving data from the League API.")
+}
 
-    resp, err := http.Get(requrl)
-    if err != nil {
-        if resp.StatusCode != 200 && resp.StatusCode != 404 {
-            log.Printf("Error in getStatus:\n%v\n", err)
-            return nil, "Error retrieving data from API"
-        }
+// shown instead of an error when we're out of requests for now
+func leagueBusyEmbed(err error) *discordgo.MessageEmbed {
+    embed := &discordgo.MessageEmbed{
+        Color: 0xD13739,
+        Title: "League API is busy",
+        Description: "Too many League commands are being used right now. Try again in a bit!",
     }
-    defer resp.Body.Close()
-
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        log.Printf("Error in getStatus:\n%v\n", err)
-        return nil, "Error reading data from API"
+    var rerr *RiotError
+    if errors.As(err, &rerr) && rerr.RetryAfter > 0 {
+        embed.Description = fmt.Sprintf("Too many League commands are being used right now. Try again in %v seconds!", int(math.Ceil(rerr.RetryAfter.Seconds())))
     }
+    return embed
+}
 
-    status := &ServerStatus{}
-    err = json.Unmarshal(body, &status)
+func (helper *LeagueHelper) GetStatus(ctx context.Context, region *LeagueRegion) (*PlatformStatus, error) {
+    status := &PlatformStatus{}
+    err := helper.Client.Get(ctx, region.Platform, SERVER_STATUS, status)
     if err != nil {
-        log.Printf("Error in getStatus:\n%v\n", err)
-        return nil, "Error parsing data from the API"
+        return nil, err
     }
-
-    return status, ""
+    return status, nil
 }
 
-func (helper *LeagueHelper) GetStatusEmbed() *discordgo.MessageEmbed {
-    embed := &discordgo.MessageEmbed{}
-
-    status, err := helper.getStatus()
-    if err != "" {
-        return MakeErrorEmbed(err)
+// the english version of some status text, or whatever is first if there isn't one
+func statusContent(contents []*StatusContent) string {
+    for _, c := range(contents) {
+        if c.Locale == "en_US" {
+            return c.Content
+        }
     }
-
-    if status.Status != nil {
-        return MakeErrorEmbed(status.Status.Message)
+    if len(contents) > 0 {
+        return contents[0].Content
     }
-
-    embed.Title = "Server Status (NA1)"
-    embed.Color = 0xD13739
-    for _, service := range(status.Services) {
-        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-            Name: service.Name,
-            Value: strings.Title(service.Status),
-        })
-    }
-
-    return embed
+    return ""
 }
 
-func (helper LeagueHelper) GetSummoner(summonername string) (*Summoner, string) {
-    requrl := fmt.Sprintf(API_URL+SUMMONER, url.QueryEscape(summonername)) + "?api_key=" + helper.Token
-    requrl = strings.ReplaceAll(requrl, "+", "%20")
+func (helper *LeagueHelper) GetStatusEmbed(region *LeagueRegion) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
+    embed := &discordgo.MessageEmbed{}
 
-    resp, err := http.Get(requrl)
+    status, err := helper.GetStatus(ctx, region)
     if err != nil {
-        if resp.StatusCode != 200 && resp.StatusCode != 404 {
-            log.Printf("Error in GetSummoner:\n%v\n", err)
-            return nil, "Error retrieving data from API"
-        }
+        return riotErrorEmbed("GetStatusEmbed", err, "Region")
     }
-    defer resp.Body.Close()
 
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        log.Printf("Error in GetSummoner:\n%v\n", err)
-        return nil, "Error reading API data"
+    embed.Title = "Server Status: " + region.String()
+    embed.Color = 0xD13739
+    for _, s := range(append(status.Maintenances, status.Incidents...)) {
+        f := &discordgo.MessageEmbedField{
+            Name: statusContent(s.Titles),
+            Value: "No details yet.",
+        }
+        if len(s.Updates) > 0 {
+            f.Value = statusContent(s.Updates[len(s.Updates)-1].Translations)
+        }
+        if f.Name == "" {
+            f.Name = "Unnamed issue"
+        }
+        embed.Fields = append(embed.Fields, f)
     }
-    
-    summ := &Summoner{}
-    err = json.Unmarshal(body, summ)
-    if err != nil {
-        log.Printf("Error in GetSummoner:\n%v\n", err)
-        return nil, "Error parsing API data"
+    if len(embed.Fields) == 0 {
+        embed.Description = "Everything is working normally."
+    }
+    // discord only allows 25 fields
+    if len(embed.Fields) > 25 {
+        embed.Fields = embed.Fields[:25]
     }
 
-    return summ, ""
+    return embed
 }
 
-func (helper LeagueHelper) GetMasteryScore(summonerID string) (int, string) {
-    requrl := fmt.Sprintf(API_URL+MASTERY_SCORE + "?api_key=" + helper.Token, summonerID)
-    
-    waserr := false
-    resp, err := http.Get(requrl)
-    if err != nil {
-        if resp != nil {
-            if resp.StatusCode != 200 && resp.StatusCode != 404 {
-                log.Printf("Error in GetMasteryScore:\n%v\n", err)
-                return -1, "Error retrieving data from API"
-            } else {
-                waserr = true
-            }
-        } else {
-            log.Printf("Error in GetMasteryScore:\n%v\n", err)
-            return -1, "Error retrieving data from API"
-        }
+// splits a Riot ID like "Some Name#TAG". names without a tag get the region's default one,
+// which is what riot gave everyone when summoner names went away
+func parseRiotID(riotid string, region *LeagueRegion) (string, string) {
+    riotid = strings.TrimSpace(riotid)
+    if i := strings.LastIndex(riotid, "#"); i != -1 {
+        return strings.TrimSpace(riotid[:i]), strings.TrimSpace(riotid[i+1:])
     }
-    defer resp.Body.Close()
+    return riotid, region.Tag
+}
 
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        log.Printf("Error in GetMasteryScore:\n%v\n", err)
-        return -1, "Error reading API data"
+// riot ignores case in Riot IDs, so the cache can too. spaces matter, though
+func (helper *LeagueHelper) GetAccount(ctx context.Context, region *LeagueRegion, riotid string) (*RiotAccount, error) {
+    gamename, tagline := parseRiotID(riotid, region)
+    if gamename == "" || tagline == "" {
+        return nil, &RiotError{ StatusCode: http.StatusNotFound, Message: "no such Riot ID" }
     }
-
-    if !waserr {
-        mastery, err := strconv.Atoi(string(body))
-        if err != nil {
-            log.Printf("Error in GetMasteryScore:\n%v\n", err)
-            return -1, "Error parsing API data"
-        }
-
-        return mastery, ""
-    } else {
-        var e GenericLeagueError
-        err = json.Unmarshal(body, &e)
-        if err != nil {
-            log.Printf("Error in GetMasteryScore:\n%v\n", err)
-            return -1, "Error parsing error data from API"
-        }
-        log.Printf("Error in GetMasteryScore:\n%v\n", e.Status.Message)
-        return -1, e.Status.Message
+    account := &RiotAccount{}
+    err := helper.Client.Get(ctx, region.AccountHost(), ACCOUNT_BY_RIOT_ID, account,
+        url.PathEscape(strings.ToLower(gamename)), url.PathEscape(strings.ToLower(tagline)))
+    if err != nil {
+        return nil, err
     }
+    return account, nil
 }
 
-func (helper LeagueHelper) GetSummonerMasteries(summonerID string) (ChampionMasteries, string) {
-    requrl := fmt.Sprintf(API_URL+ALL_CHAMPION_MASTERY+"?api_key="+helper.Token, summonerID)
-    
-    waserr := false
-    resp, err := http.Get(requrl)
+func (helper *LeagueHelper) GetSummonerByPUUID(ctx context.Context, region *LeagueRegion, puuid string) (*Summoner, error) {
+    summ := &Summoner{}
+    err := helper.Client.Get(ctx, region.Platform, SUMMONER, summ, puuid)
     if err != nil {
-        if resp != nil {
-            if resp.StatusCode != 200 && resp.StatusCode != 404 {
-                log.Printf("Error in GetSummonerMasteries:\n%v\n", err)
-                return nil, "Error retrieving data from API"
-            } else {
-                waserr = true
-            }
-        } else {
-            log.Printf("Error in GetSummonerMasteries:\n%v\n", err)
-            return nil, "Error retrieving data from API"
-        }
+        return nil, err
     }
-    defer resp.Body.Close()
+    return summ, nil
+}
 
-    body, err := ioutil.ReadAll(resp.Body)
+// looks up a summoner by Riot ID (see parseRiotID). their Name is set to the Riot ID,
+// since summoners don't have names of their own anymore
+func (helper *LeagueHelper) GetSummoner(ctx context.Context, region *LeagueRegion, riotid string) (*Summoner, error) {
+    account, err := helper.GetAccount(ctx, region, riotid)
     if err != nil {
-        log.Printf("Error in GetSummonerMasteries:\n%v\n", err)
-        return nil, "Error reading API data"
+        return nil, err
     }
-
-    if waserr {
-        lerr := &GenericLeagueError{}
-        err = json.Unmarshal(body, lerr)
-        if err != nil {
-            log.Println("Error in GetSummonerMasteries:\n%v\n", err)
-            return nil, "Error parsing API data"
-        }
-        return nil, lerr.Status.Message
-    }
-
-    var m ChampionMasteries
-
-    err = json.Unmarshal(body, &m)
+    summ, err := helper.GetSummonerByPUUID(ctx, region, account.PUUID)
     if err != nil {
-        log.Println("Error in GetSummonerMasteries:\n%v\n", err)
-        return nil, "Error parsing API data"
+        return nil, err
     }
-
-    return m, ""
-    
+    summ.Name = account.RiotID()
+    return summ, nil
 }
 
-func (helper LeagueHelper) GetSummonerMasteryForChampion(summonerID, champ string) (*ChampionMasteryDTO, string) {
-    cid := helper.getChampionIDByName(champ)
-    if cid == -1 {
-        return nil, "Champion not found: " + champ
-    }
-
-    requrl := fmt.Sprintf(API_URL+ALL_CHAMPION_MASTERY+BY_CHAMPION + "?api_key=" + helper.Token, summonerID, cid)
-   
-    waserr := false
-    resp, err := http.Get(requrl)
+func (helper *LeagueHelper) GetMasteryScore(ctx context.Context, region *LeagueRegion, puuid string) (int, error) {
+    var mastery int
+    err := helper.Client.Get(ctx, region.Platform, MASTERY_SCORE, &mastery, puuid)
     if err != nil {
-        if resp != nil {
-            if resp.StatusCode != 200 && resp.StatusCode != 404 {
-                log.Printf("Error in GetSummonerMasteryForChampion:\n%v\n", err)
-                return nil, "Error retrieving data from API"
-            } else {
-                waserr = true
-            }
-        } else {
-            log.Printf("Error in GetSummonerMasteryForChampion:\n%v\n", err)
-            return nil, "Error retrieving data from API"
-        }
+        return -1, err
     }
-    defer resp.Body.Close()
+    return mastery, nil
+}
 
-    body, err := ioutil.ReadAll(resp.Body)
+func (helper *LeagueHelper) GetSummonerMasteries(ctx context.Context, region *LeagueRegion, puuid string) (ChampionMasteries, error) {
+    var m ChampionMasteries
+    err := helper.Client.Get(ctx, region.Platform, ALL_CHAMPION_MASTERY, &m, puuid)
     if err != nil {
-        log.Printf("Error in GetSummonerMasteryForChampion\n%v\n", err)
-        return nil, "Error reading API data"
-    }
-
-    if waserr {
-        lerr := &GenericLeagueError{}
-        err = json.Unmarshal(body, lerr)
-        if err != nil {
-            log.Println("Error in GetSummonerMasteryForChampion:\n%v\n", err)
-            return nil, "Error parsing API data"
-        }
-        return nil, lerr.Status.Message
+        return nil, err
     }
+    return m, nil
+}
 
+func (helper *LeagueHelper) GetSummonerMasteryForChampion(ctx context.Context, region *LeagueRegion, puuid string, championID int) (*ChampionMasteryDTO, error) {
     mastery := &ChampionMasteryDTO{}
-
-    err = json.Unmarshal(body, mastery)
+    err := helper.Client.Get(ctx, region.Platform, ALL_CHAMPION_MASTERY+BY_CHAMPION, mastery, puuid, championID)
     if err != nil {
-        log.Println("Error in GetSummonerMasteryForChampion:\n%v\n", err)
-        return nil, "Error parsing API data"
+        return nil, err
     }
-
-    return mastery, ""
+    return mastery, nil
 }
 
-func (helper *LeagueHelper) GetSummonerEmbed(summonername string) *discordgo.MessageEmbed {
-    helper.Lock.Lock()
-    defer helper.Lock.Unlock()
+func (helper *LeagueHelper) GetSummonerEmbed(region *LeagueRegion, who SummonerLookup) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
     embed := &discordgo.MessageEmbed{}
 
-    summoner, err := helper.GetSummoner(summonername)
-    if err != "" {
-        log.Printf("Error in GetSummonerEmbed:\n%v\n", err)
-        return MakeErrorEmbed(err)
+    summoner, err := helper.LookupSummoner(ctx, region, who)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerEmbed", err, "Summoner")
     }
-    if summoner.Status != nil {
-        //log.Printf("Error in GetSummonerEmbed:\n%v\n", summoner.Status.Message)
-        return MakeErrorEmbed(summoner.Status.Message)
+
+    mastery, err := helper.GetMasteryScore(ctx, region, summoner.PUUID)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerEmbed", err, "Mastery score")
     }
 
-    mastery, err := helper.GetMasteryScore(summoner.ID)
-    if err != "" {
-        return MakeErrorEmbed(err)
+    entries, err := helper.GetLeagueEntries(ctx, region, summoner.PUUID)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerEmbed", err, "Ranked data")
     }
 
     updatetime := time.Unix(summoner.RevisionDate / 1000, 0)
     updatestamp := updatetime.Format(time.RFC1123)
+    version := helper.CurrentVersion()
 
     embed.Color = 0xD13739
+    // the emblem for their best queue, or just their icon if they're unranked
     embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
-        URL: summoner.GetIconURL(helper.Version),
+        URL: summoner.GetIconURL(version),
+    }
+    if best := entries.Best(); best != nil {
+        embed.Thumbnail.URL = best.EmblemURL()
     }
     embed.Title = "Summoner: " + summoner.Name
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: region.String(),
+        IconURL: summoner.GetIconURL(version),
+    }
     embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
         Name: "Level",
         Value: fmt.Sprintf("%v", summoner.Level),
+        Inline: true,
     }, &discordgo.MessageEmbedField{
         Name: "Mastery",
         Value: strconv.Itoa(mastery),
-    }, &discordgo.MessageEmbedField{
+        Inline: true,
+    })
+    for _, queue := range(RankedQueues) {
+        f := &discordgo.MessageEmbedField{
+            Name: queue.Name,
+            Value: "Unranked",
+        }
+        if e := entries.Queue(queue.Type); e != nil {
+            f.Value = e.Summary()
+        }
+        embed.Fields = append(embed.Fields, f)
+    }
+    embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
         Name: "Updated",
         Value: updatestamp,
     })
@@ -515,29 +506,24 @@ func (helper *LeagueHelper) GetSummonerEmbed(summonername string) *discordgo.Mes
     return embed
 }
 
-func (helper *LeagueHelper) GetSummonerMasteriesEmbed(summonername string) *discordgo.MessageEmbed {
-    helper.Lock.Lock()
-    defer helper.Lock.Unlock()
+func (helper *LeagueHelper) GetSummonerMasteriesEmbed(region *LeagueRegion, who SummonerLookup) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
     embed := &discordgo.MessageEmbed{}
 
-    summoner, err := helper.GetSummoner(summonername)
-    if err != "" {
-        log.Printf("Error in GetSummonerMasteriesEmbed:\n%v\n", err)
-        return MakeErrorEmbed(err)
-    }
-    if summoner.Status != nil {
-        //log.Printf("Error in GetSummonerEmbed:\n%v\n", summoner.Status.Message)
-        return MakeErrorEmbed(summoner.Status.Message)
+    summoner, err := helper.LookupSummoner(ctx, region, who)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerMasteriesEmbed", err, "Summoner")
     }
     
-    mastery, err := helper.GetMasteryScore(summoner.ID)
-    if err != "" {
-        return MakeErrorEmbed(err)
+    mastery, err := helper.GetMasteryScore(ctx, region, summoner.PUUID)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerMasteriesEmbed", err, "Mastery score")
     }
 
-    masteries, err := helper.GetSummonerMasteries(summoner.ID)
-    if err != "" {
-        return MakeErrorEmbed(err)
+    masteries, err := helper.GetSummonerMasteries(ctx, region, summoner.PUUID)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerMasteriesEmbed", err, "Champion masteries")
     }
 
     totalpoints := 0
@@ -545,13 +531,19 @@ func (helper *LeagueHelper) GetSummonerMasteriesEmbed(summonername string) *disc
         totalpoints += m.ChampionPoints
     }
 
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
     embed.Color = 0xD13739
     embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
         URL: summoner.GetIconURL(helper.Version),
     }
     embed.Title = "Summoner Masteries: " + summoner.Name
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: region.String(),
+    }
     embed.Description = fmt.Sprintf("**Mastery level: %v**\nTotal mastery points: %v", mastery, totalpoints)
-    for i := 0; i < 5; i++ {
+    // newer accounts might not have played 5 champions yet
+    for i := 0; i < 5 && i < len(masteries); i++ {
         embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
             Name: helper.getChampionNameByID(masteries[i].ChampionID),
             Value: fmt.Sprintf("Level %v, %vpts", masteries[i].ChampionLevel, masteries[i].ChampionPoints),
@@ -561,38 +553,37 @@ func (helper *LeagueHelper) GetSummonerMasteriesEmbed(summonername string) *disc
     return embed
 }
 
-func (helper *LeagueHelper) GetSummonerMasteryEmbed(summonername, champname string) *discordgo.MessageEmbed {
-    helper.Lock.Lock()
-    defer helper.Lock.Unlock()
+func (helper *LeagueHelper) GetSummonerMasteryEmbed(region *LeagueRegion, who SummonerLookup, champname string) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
     embed := &discordgo.MessageEmbed{}
 
-    summoner, err := helper.GetSummoner(summonername)
-    if err != "" {
-        log.Printf("Error in GetSummonerMasteriesEmbed:\n%v\n", err)
-        return MakeErrorEmbed(err)
-    }
-    if summoner.Status != nil {
-        //log.Printf("Error in GetSummonerEmbed:\n%v\n", summoner.Status.Message)
-        return MakeErrorEmbed(summoner.Status.Message)
+    // get details for champ
+    champ, suggestions := helper.ResolveChampion(champname)
+    if champ == nil {
+        return championNotFoundEmbed(champname, suggestions)
     }
+    cid, _ := strconv.Atoi(champ.Key)
 
-    mastery, err := helper.GetSummonerMasteryForChampion(summoner.ID, champname)
-    if err != "" {
-        return MakeErrorEmbed(err)
+    summoner, err := helper.LookupSummoner(ctx, region, who)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerMasteryEmbed", err, "Summoner")
     }
 
-    // get details for champ
-    champ := helper.ChampionData.Data[sanitizeChampionName(champname)]
+    mastery, err := helper.GetSummonerMasteryForChampion(ctx, region, summoner.PUUID, cid)
+    if err != nil {
+        return riotErrorEmbed("GetSummonerMasteryEmbed", err, "Mastery for " + champ.Name)
+    }
 
     lastplaytime := time.Unix(mastery.LastPlayTime / 1000, 0)
     lastplaytimestamp := lastplaytime.Format(time.RFC1123)
 
     embed.Color = 0xD13739
     embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
-        URL: champ.Image.GetURL(helper.Version),
+        URL: champ.Image.GetURL(helper.CurrentVersion()),
     }
     embed.Title = "Champion Mastery: " + champ.Name
-    embed.Description = "For summoner " + summoner.Name
+    embed.Description = fmt.Sprintf("For summoner %v on %v", summoner.Name, region)
     embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
         Name: "Level",
         Value: fmt.Sprintf("%v", mastery.ChampionLevel),
@@ -617,18 +608,19 @@ func sanitizeDescription(desc string) string {
     return htmltag.ReplaceAllString(brtag.ReplaceAllString(desc, "\n"), "*")
 }
 
-func (helper *LeagueHelper) GetChampionEmbed(champname string) *discordgo.MessageEmbed {
+// level is 0 to show base stats and their growth instead of the stats at a level
+func (helper *LeagueHelper) GetChampionEmbed(champname string, level int) *discordgo.MessageEmbed {
     embed := &discordgo.MessageEmbed{}
     
-    cdata, found := helper.ChampionData.Data[sanitizeChampionName(champname)]
-    if !found {
-        return MakeErrorEmbed("Error: Champion not found")
+    cdata, suggestions := helper.ResolveChampion(champname)
+    if cdata == nil {
+        return championNotFoundEmbed(champname, suggestions)
     }
 
     embed.Color = 0xD13739
     embed.Title = "Champion: " + cdata.Name
     embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
-        URL: cdata.Image.GetURL(helper.Version),
+        URL: cdata.Image.GetURL(helper.CurrentVersion()),
     }
     embed.Description = fmt.Sprintf("**%v**\n*", strings.Title(cdata.Title))
     for i, t := range(cdata.Tags) {
@@ -645,6 +637,24 @@ func (helper *LeagueHelper) GetChampionEmbed(champname string) *discordgo.Messag
         Name: "Resource",
         Value: cdata.Resource,
     })
+    if cdata.Stats != nil {
+        f := &discordgo.MessageEmbedField{
+            Name: "Base Stats",
+            Value: championStatLines(cdata.Stats, level),
+            Inline: true,
+        }
+        if level > 0 {
+            f.Name = fmt.Sprintf("Stats at Level %v", level)
+        }
+        embed.Fields = append(embed.Fields, f)
+    }
+    if cdata.Info != nil {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: "Ratings",
+            Value: championRatings(cdata.Info),
+            Inline: true,
+        })
+    }
 
     spellLabels := "QWER"
     for i, spell := range(cdata.Spells) {
@@ -0,0 +1,194 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// how often everyone's ranks are checked. the checks are spread out over this time,
+// so they don't compete with people's commands for the rate limit
+const RankPollInterval = 15 * time.Minute
+
+// the part of a ranked entry worth remembering between checks. LP is left out, so the
+// store is only written when someone changes division, not after every game
+type RankSnapshot struct {
+    Tier    string
+    Rank    string
+}
+
+func snapshotRank(e *LeagueEntryDTO) *RankSnapshot {
+    return &RankSnapshot{ Tier: e.Tier, Rank: e.Rank }
+}
+
+func (r *RankSnapshot) entry() *LeagueEntryDTO {
+    return &LeagueEntryDTO{ Tier: r.Tier, Rank: r.Rank }
+}
+
+// higher is better: each tier counts (apex tiers too), and each division within one
+func (r *RankSnapshot) level() int {
+    level := 0
+    for i, t := range(RankTiers) {
+        if t == r.Tier {
+            level = i * len(rankDivisions)
+        }
+    }
+    if apexTiers[r.Tier] {
+        return level
+    }
+    for i, d := range(rankDivisions) {
+        if d == r.Rank {
+            level += i
+        }
+    }
+    return level
+}
+
+// the guilds that want announcements about the user, and their channels
+func announceChannels(link *LeagueLink) map[string]string {
+    channels := make(map[string]string)
+    for _, g := range(link.Guilds) {
+        if c := GetGuildSettings(g).LeagueAnnounceChannel; c != "" {
+            channels[g] = c
+        }
+    }
+    return channels
+}
+
+// what rank changes need to be posted in a channel
+const announcePermissions = discordgo.PermissionViewChannel | discordgo.PermissionSendMessages | discordgo.PermissionEmbedLinks
+
+// checks that the channel is a text channel in the guild that the bot can post rank changes in.
+// the error is meant for the user
+func checkAnnounceChannel(s *discordgo.Session, guildID, channelID string) error {
+    channel, err := s.State.Channel(channelID)
+    if err != nil {
+        channel, err = s.Channel(channelID)
+    }
+    if err != nil || channel.GuildID != guildID {
+        return errors.New("That channel isn't in this server.")
+    }
+    if channel.Type != discordgo.ChannelTypeGuildText && channel.Type != discordgo.ChannelTypeGuildNews {
+        return errors.New("Rank changes can only be posted in text channels.")
+    }
+    perms, err := s.UserChannelPermissions(s.State.User.ID, channelID)
+    if err != nil {
+        log.Printf("Error in checkAnnounceChannel:\n%v\n", err)
+        return errors.New("Something went wrong, please try again later. Sorry! :(")
+    }
+    if missing := announcePermissions &^ perms; missing != 0 {
+        return fmt.Errorf("I can't post in <#%v> without these permissions: %v.", channelID, PermissionString(missing))
+    }
+    return nil
+}
+
+// e.g. "<@123> (**Some Name#TAG**) climbed from Gold I to Platinum IV in Ranked Solo/Duo!"
+func rankChangeEmbed(userID string, link *LeagueLink, queue string, old, cur *RankSnapshot) *discordgo.MessageEmbed {
+    embed := &discordgo.MessageEmbed{
+        Color: 0xD13739,
+        Thumbnail: &discordgo.MessageEmbedThumbnail{
+            URL: cur.entry().EmblemURL(),
+        },
+    }
+    if cur.level() > old.level() {
+        embed.Title = "📈 Promoted!"
+        embed.Description = fmt.Sprintf("<@%v> (**%v**) climbed from %v to %v in %v!",
+            userID, link.RiotID, old.entry().TierString(), cur.entry().TierString(), queue)
+    } else {
+        embed.Title = "📉 Demoted"
+        embed.Description = fmt.Sprintf("<@%v> (**%v**) dropped from %v to %v in %v.",
+            userID, link.RiotID, old.entry().TierString(), cur.entry().TierString(), queue)
+    }
+    return embed
+}
+
+// compares the user's ranks against the last check, announces any new divisions, and saves them
+func checkRank(s *discordgo.Session, userID string, link *LeagueLink) error {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
+    region := LeagueData.Region("", link.Region)
+    entries, err := LeagueData.GetLeagueEntries(ctx, region, link.PUUID)
+    if err != nil {
+        return err
+    }
+
+    ranks := make(map[string]*RankSnapshot)
+    changed := false
+    for _, queue := range(RankedQueues) {
+        e := entries.Queue(queue.Type)
+        if e == nil {
+            continue
+        }
+        cur := snapshotRank(e)
+        ranks[queue.Type] = cur
+        old, ok := link.Ranks[queue.Type]
+        if !ok || *old != *cur {
+            changed = true
+        }
+        // the first time we see a queue, there's nothing to compare to
+        if !ok || old.level() == cur.level() {
+            continue
+        }
+        embed := rankChangeEmbed(userID, link, queue.Name, old, cur)
+        for _, channelID := range(announceChannels(link)) {
+            _, err := s.ChannelMessageSendEmbed(channelID, embed)
+            if err != nil {
+                log.Printf("Error in checkRank:\n%v\n", err)
+            }
+        }
+    }
+    // a queue they dropped out of (like after a season reset) counts as a change too
+    if !changed && len(ranks) == len(link.Ranks) {
+        return nil
+    }
+
+    return UpdateUserData(userID, func(ud *UserData) {
+        // they might have unlinked or linked another account since
+        if ud.League != nil && ud.League.PUUID == link.PUUID {
+            ud.League.Ranks = ranks
+        }
+    })
+}
+
+// the linked users someone wants announcements about
+func rankWatchUsers() []string {
+    var users []string
+    for _, userID := range(DB.Keys(UserBucket)) {
+        link := GetLeagueLink(userID)
+        if link != nil && len(announceChannels(link)) > 0 {
+            users = append(users, userID)
+        }
+    }
+    return users
+}
+
+// should only be run in a separate goroutine
+func RankWatchRoutine(s *discordgo.Session) {
+    for {
+        start := time.Now()
+        users := rankWatchUsers()
+        gap := RankPollInterval / time.Duration(len(users) + 1)
+        for _, userID := range(users) {
+            link := GetLeagueLink(userID)
+            if link == nil {
+                continue
+            }
+            err := checkRank(s, userID, link)
+            var rerr *RiotError
+            if errors.As(err, &rerr) && rerr.RetryAfter > gap {
+                // riot wants us to back off for longer than we would anyway
+                time.Sleep(rerr.RetryAfter)
+            } else if err != nil {
+                log.Printf("Error checking League rank for %v:\n%v\n", userID, err)
+            }
+            time.Sleep(gap)
+        }
+        if wait := RankPollInterval - time.Since(start); wait > 0 {
+            time.Sleep(wait)
+        }
+    }
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// how many "did you mean" suggestions are shown
+const MaxChampionSuggestions = 3
+
+// what people call champions, to their keys in ChampionData. names that already work
+// (like "wukong" or "nunu") don't need to be here.
+var ChampionNicknames = map[string]string {
+    "asol": "aurelionsol",
+    "blitz": "blitzcrank",
+    "cait": "caitlyn",
+    "cass": "cassiopeia",
+    "ez": "ezreal",
+    "fiddle": "fiddlesticks",
+    "gp": "gangplank",
+    "heimer": "heimerdinger",
+    "j4": "jarvaniv",
+    "jarvan": "jarvaniv",
+    "kata": "katarina",
+    "kog": "kogmaw",
+    "lb": "leblanc",
+    "lee": "leesin",
+    "malph": "malphite",
+    "mf": "missfortune",
+    "morg": "morgana",
+    "mundo": "drmundo",
+    "naut": "nautilus",
+    "noc": "nocturne",
+    "rek": "reksai",
+    "tf": "twistedfate",
+    "tk": "tahmkench",
+    "trist": "tristana",
+    "vlad": "vladimir",
+    "voli": "volibear",
+    "ww": "warwick",
+    "xin": "xinzhao",
+    "yi": "masteryi",
+}
+
+// only letters and numbers, lowercased, so "Kog'Maw", "Dr. Mundo" and "Nunu & Willump" are easy to match
+func championSearchName(name string) string {
+    return strings.Map(func(r rune) rune {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) {
+            return unicode.ToLower(r)
+        }
+        return -1
+    }, name)
+}
+
+// the number of single letter insertions, deletions and substitutions to turn a into b
+func editDistance(a, b string) int {
+    x, y := []rune(a), []rune(b)
+    prev := make([]int, len(y) + 1)
+    cur := make([]int, len(y) + 1)
+    for j := range(prev) {
+        prev[j] = j
+    }
+    for i := 1; i <= len(x); i++ {
+        cur[0] = i
+        for j := 1; j <= len(y); j++ {
+            cost := 1
+            if x[i - 1] == y[j - 1] {
+                cost = 0
+            }
+            cur[j] = min3(prev[j] + 1, cur[j - 1] + 1, prev[j - 1] + cost)
+        }
+        prev, cur = cur, prev
+    }
+    return prev[len(y)]
+}
+
+func min3(a, b, c int) int {
+    if b < a {
+        a = b
+    }
+    if c < a {
+        a = c
+    }
+    return a
+}
+
+// finds a champion by name, ID ("MonkeyKing"), nickname, or the start of a name. if there's
+// no single match, it returns nil and the names of champions they might have meant, if any.
+// it takes the read lock itself, so don't call it while holding the helper's lock.
+func (helper *LeagueHelper) ResolveChampion(name string) (*ChampionDTO, []string) {
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+    s := championSearchName(name)
+    if s == "" || helper.ChampionData == nil {
+        return nil, nil
+    }
+
+    if c, ok := helper.ChampionData.Data[s]; ok {
+        return c, nil
+    }
+    for _, c := range(helper.ChampionData.Data) {
+        if championSearchName(c.Name) == s {
+            return c, nil
+        }
+    }
+    if key, ok := ChampionNicknames[s]; ok {
+        if c, ok := helper.ChampionData.Data[key]; ok {
+            return c, nil
+        }
+    }
+
+    var prefixed []*ChampionDTO
+    for key, c := range(helper.ChampionData.Data) {
+        if strings.HasPrefix(key, s) || strings.HasPrefix(championSearchName(c.Name), s) {
+            prefixed = append(prefixed, c)
+        }
+    }
+    if len(prefixed) == 1 {
+        return prefixed[0], nil
+    }
+    if len(prefixed) > 1 {
+        names := make([]string, 0, len(prefixed))
+        for _, c := range(prefixed) {
+            names = append(names, c.Name)
+        }
+        sort.Strings(names)
+        if len(names) > MaxChampionSuggestions {
+            names = names[:MaxChampionSuggestions]
+        }
+        return nil, names
+    }
+
+    // probably a typo; longer names can be further off
+    limit := 1
+    if len(s) >= 5 {
+        limit = 2
+    }
+    type suggestion struct {
+        name    string
+        dist    int
+    }
+    var near []suggestion
+    for key, c := range(helper.ChampionData.Data) {
+        d := editDistance(s, key)
+        if dn := editDistance(s, championSearchName(c.Name)); dn < d {
+            d = dn
+        }
+        if d <= limit {
+            near = append(near, suggestion{ c.Name, d })
+        }
+    }
+    sort.Slice(near, func(i, j int) bool {
+        if near[i].dist != near[j].dist {
+            return near[i].dist < near[j].dist
+        }
+        return near[i].name < near[j].name
+    })
+    var names []string
+    for i := 0; i < len(near) && i < MaxChampionSuggestions; i++ {
+        names = append(names, near[i].name)
+    }
+    return nil, names
+}
+
+// e.g. "Champion not found: atrox. Did you mean **Aatrox**?"
+func championNotFoundEmbed(name string, suggestions []string) *discordgo.MessageEmbed {
+    msg := "Champion not found: " + name
+    if len(suggestions) > 0 {
+        for i, s := range(suggestions) {
+            suggestions[i] = "**" + s + "**"
+        }
+        msg += fmt.Sprintf(". Did you mean %v?", joinOr(suggestions))
+    }
+    return MakeErrorEmbed(msg)
+}
+
+// e.g. "a, b or c"
+func joinOr(words []string) string {
+    if len(words) < 2 {
+        return strings.Join(words, "")
+    }
+    return strings.Join(words[:len(words) - 1], ", ") + " or " + words[len(words) - 1]
+}
+
+// champions can't level past this
+const MaxChampionLevel = 18
+
+// how many levels' worth of per-level growth a champion has at a level. riot's growth
+// isn't linear: it's slower early on and faster later, adding up to 17 levels' worth at 18
+func statGrowth(level int) float64 {
+    n := float64(level - 1)
+    return n * (0.7025 + 0.0175 * n)
+}
+
+// one row in champion stat listings. value gives the stat at a level, and perLevel the growth
+// shown when no level is asked for ("" if it doesn't grow)
+type championStat struct {
+    name        string
+    value       func(s *ChampionStatsDTO, level int) float64
+    perLevel    func(s *ChampionStatsDTO) string
+}
+
+var championStats = []championStat {
+    { "HP", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.HP) + float64(s.HPPerLevel) * statGrowth(level)
+    }, func(s *ChampionStatsDTO) string {
+        return formatStat(float64(s.HPPerLevel))
+    } },
+    { "Armor", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.Armor) + float64(s.ArmorPerLevel) * statGrowth(level)
+    }, func(s *ChampionStatsDTO) string {
+        return formatStat(float64(s.ArmorPerLevel))
+    } },
+    { "Magic Resist", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.MagicResist) + float64(s.MagicResistPerLevel) * statGrowth(level)
+    }, func(s *ChampionStatsDTO) string {
+        return formatStat(float64(s.MagicResistPerLevel))
+    } },
+    { "Attack Damage", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.AttackDamage) + float64(s.AttackDamagePerLevel) * statGrowth(level)
+    }, func(s *ChampionStatsDTO) string {
+        return formatStat(float64(s.AttackDamagePerLevel))
+    } },
+    // attack speed grows by a percentage of the base
+    { "Attack Speed", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.AttackSpeed) * (1 + float64(s.AttackSpeedPerLevel) / 100 * statGrowth(level))
+    }, func(s *ChampionStatsDTO) string {
+        return formatStat(float64(s.AttackSpeedPerLevel)) + "%"
+    } },
+    { "Attack Range", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.AttackRange)
+    }, nil },
+    { "Move Speed", func(s *ChampionStatsDTO, level int) float64 {
+        return float64(s.MoveSpeed)
+    }, nil },
+}
+
+// up to 3 decimal places, without trailing zeroes
+func formatStat(v float64) string {
+    return strconv.FormatFloat(math.Round(v * 1000) / 1000, 'f', -1, 64)
+}
+
+// one stat per line; with a level of 0, it's the base stats and how much they grow per level
+func championStatLines(s *ChampionStatsDTO, level int) string {
+    var lines []string
+    for _, stat := range(championStats) {
+        if level > 0 {
+            lines = append(lines, fmt.Sprintf("%v: %v", stat.name, formatStat(stat.value(s, level))))
+            continue
+        }
+        line := fmt.Sprintf("%v: %v", stat.name, formatStat(stat.value(s, 1)))
+        if stat.perLevel != nil {
+            line += fmt.Sprintf(" (+%v/lvl)", stat.perLevel(s))
+        }
+        lines = append(lines, line)
+    }
+    return strings.Join(lines, "\n")
+}
+
+// riot's 0 to 10 ratings, one per line
+func championRatings(i *ChampionInfoDTO) string {
+    return fmt.Sprintf("Attack: %v/10\nDefense: %v/10\nMagic: %v/10\nDifficulty: %v/10", i.Attack, i.Defense, i.Magic, i.Difficulty)
+}
+
+// a champion's name, and a level if the last word is one, like "miss fortune 11"; level is 0 if there isn't one
+func splitChampionLevel(s string) (string, int) {
+    s = strings.TrimSpace(s)
+    i := strings.LastIndexFunc(s, unicode.IsSpace)
+    if i == -1 {
+        return s, 0
+    }
+    level, err := strconv.Atoi(s[i+1:])
+    if err != nil {
+        return s, 0
+    }
+    return strings.TrimSpace(s[:i]), level
+}
+
+// side by side stats for two champions at a level, with the better of each in bold
+func (helper *LeagueHelper) GetChampionCompareEmbed(name1, name2 string, level int) *discordgo.MessageEmbed {
+    champ1, suggestions := helper.ResolveChampion(name1)
+    if champ1 == nil {
+        return championNotFoundEmbed(name1, suggestions)
+    }
+    champ2, suggestions := helper.ResolveChampion(name2)
+    if champ2 == nil {
+        return championNotFoundEmbed(name2, suggestions)
+    }
+    if champ1.Stats == nil || champ2.Stats == nil || champ1.Info == nil || champ2.Info == nil {
+        return MakeErrorEmbed("Stats aren't available for those champions right now.")
+    }
+
+    // which value is better; higher is better for all of these
+    bold := func(a, b float64, s string) string {
+        if a > b {
+            return "**" + s + "**"
+        }
+        return s
+    }
+    var names, col1, col2 []string
+    for _, stat := range(championStats) {
+        a, b := stat.value(champ1.Stats, level), stat.value(champ2.Stats, level)
+        names = append(names, stat.name)
+        col1 = append(col1, bold(a, b, formatStat(a)))
+        col2 = append(col2, bold(b, a, formatStat(b)))
+    }
+    ratings := []struct{
+        name    string
+        a, b    int
+    }{
+        { "Attack", champ1.Info.Attack, champ2.Info.Attack },
+        { "Defense", champ1.Info.Defense, champ2.Info.Defense },
+        { "Magic", champ1.Info.Magic, champ2.Info.Magic },
+        { "Difficulty", champ1.Info.Difficulty, champ2.Info.Difficulty },
+    }
+    for _, r := range(ratings) {
+        names = append(names, r.name + " Rating")
+        col1 = append(col1, fmt.Sprintf("%v/10", r.a))
+        col2 = append(col2, fmt.Sprintf("%v/10", r.b))
+    }
+
+    return &discordgo.MessageEmbed{
+        Color: 0xD13739,
+        Title: fmt.Sprintf("%v vs. %v", champ1.Name, champ2.Name),
+        Description: fmt.Sprintf("Stats at level %v, without items or runes.", level),
+        Fields: []*discordgo.MessageEmbedField{
+            { Name: "Stat", Value: strings.Join(names, "\n"), Inline: true },
+            { Name: champ1.Name, Value: strings.Join(col1, "\n"), Inline: true },
+            { Name: champ2.Name, Value: strings.Join(col2, "\n"), Inline: true },
+        },
+        Footer: &discordgo.MessageEmbedFooter{
+            Text: "Patch " + helper.CurrentVersion(),
+        },
+    }
+}
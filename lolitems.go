@@ -0,0 +1,287 @@
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// summoner's rift, for picking between items that share a name
+const SummonersRiftMap = "11"
+
+// readable names for the stats in item.json. percent stats are given as fractions
+var ItemStatNames = map[string]string {
+    "FlatHPPoolMod": "Health",
+    "FlatMPPoolMod": "Mana",
+    "FlatHPRegenMod": "Health Regen",
+    "FlatArmorMod": "Armor",
+    "FlatSpellBlockMod": "Magic Resist",
+    "FlatPhysicalDamageMod": "Attack Damage",
+    "FlatMagicDamageMod": "Ability Power",
+    "FlatMovementSpeedMod": "Move Speed",
+    "PercentMovementSpeedMod": "% Move Speed",
+    "PercentAttackSpeedMod": "% Attack Speed",
+    "FlatCritChanceMod": "% Crit Chance",
+    "PercentLifeStealMod": "% Life Steal",
+}
+
+// lowercase without spaces and punctuation, so "bf sword" finds "B. F. Sword"
+func sanitizeItemName(name string) string {
+    return strings.Map(func(r rune) rune {
+        if r == ' ' || r == '\'' || r == '-' || r == '.' || r == ':' {
+            return -1
+        }
+        return r
+    }, strings.ToLower(name))
+}
+
+// by name or ID. exact names win over partial ones. several items can share a name (e.g. on different maps),
+// so ones on summoner's rift that can be bought are preferred, then the lowest ID.
+// returns "" and nil if nothing matches.
+func (helper *LeagueHelper) findItem(name string) (string, *ItemDTO) {
+    if helper.ItemData == nil {
+        return "", nil
+    }
+    sname := sanitizeItemName(name)
+    if sname == "" {
+        return "", nil
+    }
+    // item IDs work too
+    if item, ok := helper.ItemData.Data[sname]; ok {
+        return sname, item
+    }
+
+    score := func(item *ItemDTO) int {
+        s := 0
+        iname := sanitizeItemName(item.Name)
+        if iname == sname {
+            s += 4
+        } else if !strings.Contains(iname, sname) {
+            return -1
+        }
+        if item.Maps[SummonersRiftMap] {
+            s += 2
+        }
+        if item.Gold != nil && item.Gold.Purchasable {
+            s += 1
+        }
+        return s
+    }
+
+    var bestID string
+    var best *ItemDTO
+    bestScore := -1
+    for id, item := range(helper.ItemData.Data) {
+        s := score(item)
+        if s < 0 {
+            continue
+        }
+        if s > bestScore || (s == bestScore && itemIDLess(id, bestID)) {
+            bestID, best, bestScore = id, item, s
+        }
+    }
+    return bestID, best
+}
+
+func itemIDLess(a, b string) bool {
+    x, _ := strconv.Atoi(a)
+    y, _ := strconv.Atoi(b)
+    return x < y
+}
+
+// item IDs to names, skipping any we don't know
+func (helper *LeagueHelper) itemNames(ids []string) string {
+    var names []string
+    seen := make(map[string]bool)
+    for _, id := range(ids) {
+        if item, ok := helper.ItemData.Data[id]; ok && !seen[item.Name] {
+            seen[item.Name] = true
+            names = append(names, item.Name)
+        }
+    }
+    return strings.Join(names, ", ")
+}
+
+// e.g. "+45 Attack Damage\n+25% Attack Speed"
+func itemStats(stats map[string]float64) string {
+    keys := make([]string, 0, len(stats))
+    for k := range(stats) {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    var lines []string
+    for _, k := range(keys) {
+        v := stats[k]
+        if strings.HasPrefix(k, "Percent") || k == "FlatCritChanceMod" {
+            v *= 100
+        }
+        name, ok := ItemStatNames[k]
+        if !ok {
+            name = " " + k
+        } else if !strings.HasPrefix(name, "%") {
+            name = " " + name
+        }
+        lines = append(lines, fmt.Sprintf("+%v%v", strconv.FormatFloat(v, 'f', -1, 64), name))
+    }
+    return strings.Join(lines, "\n")
+}
+
+func (helper *LeagueHelper) GetItemEmbed(itemname string) *discordgo.MessageEmbed {
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+    embed := &discordgo.MessageEmbed{}
+
+    if helper.ItemData == nil {
+        return MakeErrorEmbed("Item data isn't available right now. Try again later.")
+    }
+    id, item := helper.findItem(itemname)
+    if item == nil {
+        return MakeErrorEmbed("Error: Item not found")
+    }
+
+    embed.Color = 0xD13739
+    embed.Title = "Item: " + item.Name
+    if item.Image != nil {
+        embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+            URL: item.Image.GetURL(helper.Version),
+        }
+    }
+    if item.Plaintext != "" {
+        embed.Description = "*" + item.Plaintext + "*\n\n"
+    }
+    embed.Description += sanitizeDescription(item.Description)
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: fmt.Sprintf("Item %v · Patch %v", id, helper.ItemData.Version),
+    }
+
+    if g := item.Gold; g != nil {
+        cost := fmt.Sprintf("%v gold", g.Total)
+        if len(item.From) > 0 {
+            cost += fmt.Sprintf(" (%v to combine)", g.Base)
+        }
+        cost += fmt.Sprintf("\nSells for %v", g.Sell)
+        if !g.Purchasable {
+            cost += "\nCan't be bought"
+        }
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: "Cost",
+            Value: cost,
+            Inline: true,
+        })
+    }
+    if stats := itemStats(item.Stats); stats != "" {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: "Stats",
+            Value: stats,
+            Inline: true,
+        })
+    }
+    if from := helper.itemNames(item.From); from != "" {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: "Builds From",
+            Value: from,
+        })
+    }
+    if into := helper.itemNames(item.Into); into != "" {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: "Builds Into",
+            Value: into,
+        })
+    }
+
+    return embed
+}
+
+// the rune's tree and which row it's in (0 is the keystones); returns nils if not found.
+// rune trees themselves can be looked up too, with a nil rune.
+func (helper *LeagueHelper) findRune(name string) (*RuneTreeDTO, int, *RuneDTO) {
+    sname := sanitizeItemName(name)
+    if sname == "" {
+        return nil, 0, nil
+    }
+    // exact names first, then partial ones
+    for _, exact := range([]bool{ true, false }) {
+        matches := func(s string) bool {
+            s = sanitizeItemName(s)
+            return s == sname || (!exact && strings.Contains(s, sname))
+        }
+        for _, tree := range(helper.RuneTrees) {
+            if matches(tree.Name) {
+                return tree, 0, nil
+            }
+            for row, slot := range(tree.Slots) {
+                for _, r := range(slot.Runes) {
+                    if matches(r.Name) {
+                        return tree, row, r
+                    }
+                }
+            }
+        }
+    }
+    return nil, 0, nil
+}
+
+func (helper *LeagueHelper) GetRuneEmbed(runename string) *discordgo.MessageEmbed {
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+    embed := &discordgo.MessageEmbed{}
+
+    if len(helper.RuneTrees) == 0 {
+        return MakeErrorEmbed("Rune data isn't available right now. Try again later.")
+    }
+    tree, row, r := helper.findRune(runename)
+    if tree == nil {
+        return MakeErrorEmbed("Error: Rune not found")
+    }
+
+    embed.Color = 0xD13739
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: "Patch " + helper.Version,
+    }
+
+    // a whole tree lists what's in each row
+    if r == nil {
+        embed.Title = "Rune Tree: " + tree.Name
+        embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+            URL: fmt.Sprintf(RUNE_ICON, tree.Icon),
+        }
+        for i, slot := range(tree.Slots) {
+            var names []string
+            for _, r := range(slot.Runes) {
+                names = append(names, r.Name)
+            }
+            embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+                Name: runeRowName(i),
+                Value: strings.Join(names, ", "),
+            })
+        }
+        return embed
+    }
+
+    embed.Title = "Rune: " + r.Name
+    embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+        URL: fmt.Sprintf(RUNE_ICON, r.Icon),
+    }
+    embed.Description = sanitizeDescription(r.LongDesc)
+    embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+        Name: "Tree",
+        Value: tree.Name,
+        Inline: true,
+    }, &discordgo.MessageEmbedField{
+        Name: "Row",
+        Value: runeRowName(row),
+        Inline: true,
+    })
+    return embed
+}
+
+func runeRowName(row int) string {
+    if row == 0 {
+        return "Keystone"
+    }
+    return fmt.Sprintf("Row %v", row)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    // how often everyone's stats are looked up again
+    LeaderboardRefresh = time.Hour
+    // how many people a leaderboard shows
+    LeaderboardSize = 10
+    // how long looking up one account can take, since the limiter may make it wait a while
+    leaderboardTimeout = 2 * time.Minute
+)
+
+// what leaderboards rank people by
+const (
+    BoardMastery    = "mastery"
+    BoardRank       = "rank"
+    BoardChampion   = "champion"
+)
+
+// what the leaderboards know about one linked account
+type LeagueStats struct {
+    RiotID      string
+    Region      string
+    TotalPoints int
+    Champions   map[int]int // mastery points by champion ID
+    Solo        *LeagueEntryDTO // nil if they're unranked in solo/duo
+    Updated     time.Time
+}
+
+// linked accounts' stats, by Discord user ID. they're refreshed in the background
+// so leaderboards don't have to ask riot about everyone in the server at once.
+type LeagueStatsCache struct {
+    stats   map[string]*LeagueStats
+    lock    sync.Mutex
+}
+
+var LeagueStatsData = &LeagueStatsCache{
+    stats: make(map[string]*LeagueStats),
+}
+
+// returns nil if they haven't been looked up yet
+func (c *LeagueStatsCache) Get(userID string) *LeagueStats {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    return c.stats[userID]
+}
+
+func (c *LeagueStatsCache) Forget(userID string) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+    delete(c.stats, userID)
+}
+
+// looks up one linked account's stats and remembers them
+func (c *LeagueStatsCache) Refresh(userID string, link *LeagueLink) error {
+    ctx, cancel := context.WithTimeout(context.Background(), leaderboardTimeout)
+    defer cancel()
+    region := LeagueData.Region("", link.Region)
+
+    stats := &LeagueStats{
+        RiotID: link.RiotID,
+        Region: region.Name,
+        Champions: make(map[int]int),
+        Updated: time.Now(),
+    }
+    if account, err := LeagueData.GetAccountByPUUID(ctx, region, link.PUUID); err == nil {
+        stats.RiotID = account.RiotID()
+    }
+    masteries, err := LeagueData.GetSummonerMasteries(ctx, region, link.PUUID)
+    if err != nil {
+        return err
+    }
+    for _, m := range(masteries) {
+        stats.TotalPoints += m.ChampionPoints
+        stats.Champions[m.ChampionID] = m.ChampionPoints
+    }
+    entries, err := LeagueData.GetLeagueEntries(ctx, region, link.PUUID)
+    if err != nil {
+        return err
+    }
+    stats.Solo = entries.Queue(RankedQueues[0].Type)
+
+    c.lock.Lock()
+    c.stats[userID] = stats
+    c.lock.Unlock()
+    return nil
+}
+
+// refreshes everyone who's linked an account
+func (c *LeagueStatsCache) RefreshAll() {
+    for _, userID := range(DB.Keys(UserBucket)) {
+        link := GetLeagueLink(userID)
+        if link == nil {
+            continue
+        }
+        err := c.Refresh(userID, link)
+        if err != nil {
+            log.Printf("Error refreshing League stats for %v:\n%v\n", userID, err)
+        }
+    }
+}
+
+// should only be run in a separate goroutine
+func (c *LeagueStatsCache) RefreshRoutine() {
+    for {
+        c.RefreshAll()
+        time.Sleep(LeaderboardRefresh)
+    }
+}
+
+type leaderboardEntry struct {
+    userID  string
+    stats   *LeagueStats
+    score   int
+}
+
+// board is one of the Board* constants; champ is only used for BoardChampion
+func (c *LeagueStatsCache) LeaderboardEmbed(guildID, board string, champ *ChampionDTO) *discordgo.MessageEmbed {
+    var entries []leaderboardEntry
+    loading := 0
+    for _, userID := range(DB.Keys(UserBucket)) {
+        link := GetLeagueLink(userID)
+        if link == nil || !link.InGuild(guildID) {
+            continue
+        }
+        stats := c.Get(userID)
+        if stats == nil {
+            loading++
+            continue
+        }
+
+        e := leaderboardEntry{ userID: userID, stats: stats }
+        switch board {
+            case BoardMastery:
+                e.score = stats.TotalPoints
+            case BoardRank:
+                if stats.Solo == nil {
+                    continue
+                }
+                e.score = stats.Solo.Score()
+            case BoardChampion:
+                e.score = stats.Champions[champKey(champ)]
+        }
+        if e.score > 0 {
+            entries = append(entries, e)
+        }
+    }
+    sort.SliceStable(entries, func(i, j int) bool {
+        return entries[i].score > entries[j].score
+    })
+
+    embed := &discordgo.MessageEmbed{
+        Color: 0xD13739,
+    }
+    switch board {
+        case BoardMastery:
+            embed.Title = "Mastery Leaderboard"
+        case BoardRank:
+            embed.Title = "Ranked Solo/Duo Leaderboard"
+        case BoardChampion:
+            embed.Title = champ.Name + " Mastery Leaderboard"
+            embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+                URL: champ.Image.GetURL(LeagueData.CurrentVersion()),
+            }
+    }
+
+    var lines []string
+    for i, e := range(entries) {
+        if i == LeaderboardSize {
+            break
+        }
+        var value string
+        switch board {
+            case BoardRank:
+                value = fmt.Sprintf("%v %v LP", e.stats.Solo.TierString(), e.stats.Solo.LeaguePoints)
+            default:
+                value = fmt.Sprintf("%v points", e.score)
+        }
+        lines = append(lines, fmt.Sprintf("%v. **%v** (<@%v>, %v) · %v", i + 1, e.stats.RiotID, e.userID, strings.ToUpper(e.stats.Region), value))
+    }
+    if len(lines) == 0 {
+        lines = append(lines, "Nobody here is on this leaderboard yet.")
+    }
+    if loading > 0 {
+        lines = append(lines, fmt.Sprintf("\n*%v more linked accounts are still being looked up.*", loading))
+    }
+    embed.Description = strings.Join(lines, "\n")
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: "Use \"lol link\" here to join · Updated hourly",
+    }
+    return embed
+}
+
+func champKey(champ *ChampionDTO) int {
+    if champ == nil {
+        return -1
+    }
+    key, _ := strconv.Atoi(champ.Key)
+    return key
+}
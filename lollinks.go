@@ -0,0 +1,117 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// a League account someone linked to their Discord account with "lol link"
+type LeagueLink struct {
+    PUUID   string
+    RiotID  string // what it was when they linked it; the current one is looked up by PUUID
+    Region  string // short name, like "euw"
+    Guilds  []string // where they linked it, so they show up on those guilds' leaderboards
+    Ranks   map[string]*RankSnapshot `json:",omitempty"` // by queue type, as of the last RankWatchRoutine check
+}
+
+// who a League command is about: a Riot ID someone typed, or a linked account's PUUID
+type SummonerLookup struct {
+    RiotID  string
+    PUUID   string
+}
+
+func (helper *LeagueHelper) GetAccountByPUUID(ctx context.Context, region *LeagueRegion, puuid string) (*RiotAccount, error) {
+    account := &RiotAccount{}
+    err := helper.Client.Get(ctx, region.AccountHost(), ACCOUNT_BY_PUUID, account, puuid)
+    if err != nil {
+        return nil, err
+    }
+    return account, nil
+}
+
+// like GetSummoner, but also takes PUUIDs, whose Riot ID is looked up in case it changed
+func (helper *LeagueHelper) LookupSummoner(ctx context.Context, region *LeagueRegion, who SummonerLookup) (*Summoner, error) {
+    if who.PUUID == "" {
+        return helper.GetSummoner(ctx, region, who.RiotID)
+    }
+    summ, err := helper.GetSummonerByPUUID(ctx, region, who.PUUID)
+    if err != nil {
+        return nil, err
+    }
+    summ.Name = who.RiotID
+    if account, err := helper.GetAccountByPUUID(ctx, region, who.PUUID); err == nil {
+        summ.Name = account.RiotID()
+    }
+    return summ, nil
+}
+
+// true if they linked their account in the guild
+func (link *LeagueLink) InGuild(guildID string) bool {
+    for _, g := range(link.Guilds) {
+        if g == guildID {
+            return true
+        }
+    }
+    return false
+}
+
+// the user's linked account, or nil
+func GetLeagueLink(userID string) *LeagueLink {
+    return GetUserData(userID).League
+}
+
+// works out who a League command is about from its summoner and region arguments: the Riot ID
+// they typed, the linked account of a user they mentioned, or their own linked account if they left
+// it out. a linked account's region is used unless they gave one. the error is meant for the user.
+func leagueTarget(ctx *CommandContext, name, regionName string) (*LeagueRegion, SummonerLookup, error) {
+    name = strings.TrimSpace(name)
+    userID := ctx.Author.ID
+    if m := userMention.FindStringSubmatch(name); m != nil {
+        userID = m[1]
+    } else if name != "" {
+        return LeagueData.Region(ctx.GuildID, regionName), SummonerLookup{ RiotID: name }, nil
+    }
+
+    link := GetLeagueLink(userID)
+    if link == nil {
+        if userID == ctx.Author.ID {
+            return nil, SummonerLookup{}, errors.New("Give me a Riot ID, like `Some Name#TAG`, or link your account with `lol link` first.")
+        }
+        return nil, SummonerLookup{}, errors.New("They haven't linked a League account.")
+    }
+    region := LeagueData.Region(ctx.GuildID, link.Region)
+    if regionName != "" {
+        region = LeagueData.Region(ctx.GuildID, regionName)
+    }
+    return region, SummonerLookup{ RiotID: link.RiotID, PUUID: link.PUUID }, nil
+}
+
+// checks that the Riot ID has a summoner on the region, and returns what to link if so
+func (helper *LeagueHelper) LinkEmbed(region *LeagueRegion, riotid string) (*discordgo.MessageEmbed, *LeagueLink) {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
+
+    summoner, err := helper.GetSummoner(ctx, region, riotid)
+    if err != nil {
+        return riotErrorEmbed("LinkEmbed", err, "Summoner"), nil
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Color: 0xD13739,
+        Title: "Linked " + summoner.Name,
+        Description: fmt.Sprintf("League commands will look up **%v** on %v when you don't give them a summoner, " +
+            "and other people can use yours by mentioning you.", summoner.Name, region),
+        Thumbnail: &discordgo.MessageEmbedThumbnail{
+            URL: summoner.GetIconURL(helper.CurrentVersion()),
+        },
+    }
+    return embed, &LeagueLink{
+        PUUID: summoner.PUUID,
+        RiotID: summoner.Name,
+        Region: region.Name,
+    }
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// team IDs in matches and live games
+const (
+    BlueTeam = 100
+    RedTeam  = 200
+)
+
+// the spell's name from summoner.json, or its ID if it's not there
+func (helper *LeagueHelper) summonerSpell(id int) string {
+    if name := helper.getSummonerSpellName(id); name != "" {
+        return name
+    }
+    return fmt.Sprintf("Spell %v", id)
+}
+
+// returns ErrRiotNotFound if they aren't in a game
+func (helper *LeagueHelper) GetActiveGame(ctx context.Context, region *LeagueRegion, puuid string) (*CurrentGameInfo, error) {
+    game := &CurrentGameInfo{}
+    err := helper.Client.Get(ctx, region.Platform, ACTIVE_GAME, game, puuid)
+    if err != nil {
+        return nil, err
+    }
+    return game, nil
+}
+
+// their Riot ID, or their summoner name from before there were Riot IDs
+func (p *CurrentGameParticipant) Name() string {
+    if p.RiotID != "" {
+        return p.RiotID
+    }
+    return p.SummonerName
+}
+
+// e.g. "Gold II", or "Unranked"; "?" if riot wouldn't say
+func (helper *LeagueHelper) shortRank(ctx context.Context, region *LeagueRegion, puuid string) string {
+    if puuid == "" {
+        return "Unranked"
+    }
+    entries, err := helper.GetLeagueEntries(ctx, region, puuid)
+    if err != nil {
+        return "?"
+    }
+    if best := entries.Best(); best != nil {
+        return best.TierString()
+    }
+    return "Unranked"
+}
+
+// everyone's shortRank, looked up at the same time; bots are "Bot"
+func (helper *LeagueHelper) participantRanks(ctx context.Context, region *LeagueRegion, participants []*CurrentGameParticipant) []string {
+    ranks := make([]string, len(participants))
+    var wg sync.WaitGroup
+    for i, p := range(participants) {
+        if p.Bot {
+            ranks[i] = "Bot"
+            continue
+        }
+        wg.Add(1)
+        go func(i int, puuid string) {
+            defer wg.Done()
+            ranks[i] = helper.shortRank(ctx, region, puuid)
+        }(i, p.PUUID)
+    }
+    wg.Wait()
+    return ranks
+}
+
+func (helper *LeagueHelper) GetLiveGameEmbed(region *LeagueRegion, who SummonerLookup) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
+    embed := &discordgo.MessageEmbed{}
+
+    summoner, err := helper.LookupSummoner(ctx, region, who)
+    if err != nil {
+        return riotErrorEmbed("GetLiveGameEmbed", err, "Summoner")
+    }
+
+    game, err := helper.GetActiveGame(ctx, region, summoner.PUUID)
+    if errors.Is(err, ErrRiotNotFound) {
+        return &discordgo.MessageEmbed{
+            Color: 0xD13739,
+            Description: fmt.Sprintf("%v isn't in a game right now.", summoner.Name),
+        }
+    } else if err != nil {
+        return riotErrorEmbed("GetLiveGameEmbed", err, "Live game")
+    }
+    ranks := helper.participantRanks(ctx, region, game.Participants)
+
+    // only the champion and spell names need the lock
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+
+    embed.Color = 0xD13739
+    embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+        URL: summoner.GetIconURL(helper.Version),
+    }
+    embed.Title = "Live Game: " + summoner.Name
+    if game.GameStartTime == 0 {
+        embed.Description = fmt.Sprintf("%v · Loading", QueueName(game.GameQueueConfigID))
+    } else {
+        length := time.Since(time.Unix(game.GameStartTime / 1000, 0))
+        embed.Description = fmt.Sprintf("%v · %v in", QueueName(game.GameQueueConfigID), formatGameDuration(length))
+    }
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: region.String(),
+    }
+
+    teams := map[int][]string{}
+    for i, p := range(game.Participants) {
+        champ := helper.getChampionNameByID(p.ChampionID)
+        if champ == "" {
+            champ = "Unknown"
+        }
+        name := p.Name()
+        if p.PUUID == summoner.PUUID {
+            name = "__" + name + "__"
+        }
+        teams[p.TeamID] = append(teams[p.TeamID], fmt.Sprintf("**%v** · %v/%v · %v · %v",
+            champ, helper.summonerSpell(p.Spell1ID), helper.summonerSpell(p.Spell2ID), name, ranks[i]))
+    }
+
+    for _, team := range([]struct{ id int; name string }{ { BlueTeam, "Blue Team" }, { RedTeam, "Red Team" } }) {
+        if len(teams[team.id]) == 0 {
+            continue
+        }
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: team.name,
+            Value: strings.Join(teams[team.id], "\n"),
+        })
+    }
+
+    return embed
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "strings"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// how many games "lol matches" shows
+const (
+    DefaultMatchCount = 5
+    MaxMatchCount     = 10
+)
+
+// queue IDs from riot's queues.json, just the ones people actually play
+var QueueNames = map[int]string {
+    0: "Custom",
+    400: "Normal Draft",
+    420: "Ranked Solo/Duo",
+    430: "Normal Blind",
+    440: "Ranked Flex",
+    450: "ARAM",
+    490: "Quickplay",
+    700: "Clash",
+    720: "ARAM Clash",
+    830: "Co-op vs. AI",
+    840: "Co-op vs. AI",
+    850: "Co-op vs. AI",
+    900: "ARURF",
+    1020: "One for All",
+    1300: "Nexus Blitz",
+    1700: "Arena",
+    1900: "URF",
+}
+
+func QueueName(id int) string {
+    if name, ok := QueueNames[id]; ok {
+        return name
+    }
+    return fmt.Sprintf("Queue %v", id)
+}
+
+// most recent first
+func (helper *LeagueHelper) GetMatchIDs(ctx context.Context, region *LeagueRegion, puuid string, count int) ([]string, error) {
+    var ids []string
+    err := helper.Client.Get(ctx, region.Regional, MATCH_IDS, &ids, puuid, count)
+    if err != nil {
+        return nil, err
+    }
+    return ids, nil
+}
+
+func (helper *LeagueHelper) GetMatch(ctx context.Context, region *LeagueRegion, matchID string) (*MatchDTO, error) {
+    match := &MatchDTO{}
+    err := helper.Client.Get(ctx, region.Regional, MATCH, match, matchID)
+    if err != nil {
+        return nil, err
+    }
+    return match, nil
+}
+
+// older matches gave their duration in milliseconds
+func (m *MatchInfoDTO) Duration() time.Duration {
+    if m.GameEndTimestamp == 0 {
+        return time.Duration(m.GameDuration) * time.Millisecond
+    }
+    return time.Duration(m.GameDuration) * time.Second
+}
+
+// when the game started
+func (m *MatchInfoDTO) Started() time.Time {
+    if m.GameStartTimestamp != 0 {
+        return time.Unix(m.GameStartTimestamp / 1000, 0)
+    }
+    return time.Unix(m.GameCreation / 1000, 0)
+}
+
+// returns nil if they weren't in the match
+func (m *MatchInfoDTO) Participant(puuid string) *ParticipantDTO {
+    for _, p := range(m.Participants) {
+        if p.PUUID == puuid {
+            return p
+        }
+    }
+    return nil
+}
+
+// like 31:02
+func formatGameDuration(d time.Duration) string {
+    return fmt.Sprintf("%v:%02d", int(d.Minutes()), int(d.Seconds()) % 60)
+}
+
+func (p *ParticipantDTO) CS() int {
+    return p.TotalMinionsKilled + p.NeutralMinionsKilled
+}
+
+// perfect games count their deaths as 1, like the client does
+func (p *ParticipantDTO) KDA() float64 {
+    deaths := p.Deaths
+    if deaths == 0 {
+        deaths = 1
+    }
+    return float64(p.Kills + p.Assists) / float64(deaths)
+}
+
+func (p *ParticipantDTO) Result() string {
+    switch {
+        case p.GameEndedInEarlySurrender:
+            return "➖ Remake"
+        case p.Win:
+            return "✅ Victory"
+    }
+    return "❌ Defeat"
+}
+
+// falls back to the name riot sends with the match, for champions newer than our data
+func (helper *LeagueHelper) participantChampion(p *ParticipantDTO) string {
+    if name := helper.getChampionNameByID(p.ChampionID); name != "" {
+        return name
+    }
+    return p.ChampionName
+}
+
+func (helper *LeagueHelper) GetMatchesEmbed(region *LeagueRegion, who SummonerLookup, count int) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
+    embed := &discordgo.MessageEmbed{}
+
+    summoner, err := helper.LookupSummoner(ctx, region, who)
+    if err != nil {
+        return riotErrorEmbed("GetMatchesEmbed", err, "Summoner")
+    }
+
+    ids, err := helper.GetMatchIDs(ctx, region, summoner.PUUID, count)
+    if err != nil {
+        return riotErrorEmbed("GetMatchesEmbed", err, "Match history")
+    }
+
+    // get every match before locking, so nobody waits on riot for the champion names
+    var matches []*MatchDTO
+    for _, id := range(ids) {
+        match, err := helper.GetMatch(ctx, region, id)
+        if err != nil {
+            // show what we have so far rather than nothing
+            if len(matches) == 0 {
+                return riotErrorEmbed("GetMatchesEmbed", err, "Match")
+            }
+            embed.Description = "Couldn't get every match; try again in a bit for the rest."
+            break
+        }
+        matches = append(matches, match)
+    }
+
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+    embed.Color = 0xD13739
+    embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+        URL: summoner.GetIconURL(helper.Version),
+    }
+    embed.Title = "Recent Matches: " + summoner.Name
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: region.String() + " · Use \"lol match <ID>\" for details",
+    }
+    if len(ids) == 0 {
+        embed.Description = "No recent matches."
+    }
+
+    for i, match := range(matches) {
+        if match.Info == nil {
+            continue
+        }
+        p := match.Info.Participant(summoner.PUUID)
+        if p == nil {
+            continue
+        }
+
+        d := match.Info.Duration()
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: fmt.Sprintf("%v · %v", p.Result(), QueueName(match.Info.QueueID)),
+            Value: fmt.Sprintf("**%v** %v/%v/%v (%.1f KDA) · %v CS (%.1f/min)\n%v · <t:%v:R> · `%v`",
+                helper.participantChampion(p), p.Kills, p.Deaths, p.Assists, p.KDA(),
+                p.CS(), float64(p.CS()) / math.Max(d.Minutes(), 1),
+                formatGameDuration(d), match.Info.Started().Unix(), ids[i]),
+        })
+    }
+
+    return embed
+}
+
+// e.g. "12.3k"
+func shortNumber(n int) string {
+    if n < 1000 {
+        return fmt.Sprint(n)
+    }
+    return fmt.Sprintf("%.1fk", float64(n) / 1000)
+}
+
+// match IDs look like NA1_1234567890. plain numbers are assumed to be from the given region
+func parseMatchID(id string, region *LeagueRegion) (string, *LeagueRegion) {
+    if i := strings.Index(id, "_"); i != -1 {
+        if r := FindLeagueRegion(id[:i]); r != nil {
+            return strings.ToUpper(id), r
+        }
+    }
+    return strings.ToUpper(region.Platform) + "_" + id, region
+}
+
+// their Riot ID, for matches from after summoner names went away
+func (p *ParticipantDTO) Name() string {
+    if p.RiotIDGameName != "" {
+        return p.RiotIDGameName + "#" + p.RiotIDTagline
+    }
+    return p.SummonerName
+}
+
+func (p *ParticipantDTO) Items() []int {
+    return []int{ p.Item0, p.Item1, p.Item2, p.Item3, p.Item4, p.Item5, p.Item6 }
+}
+
+// the keystone and both trees, e.g. "Electrocute (Domination/Sorcery)"
+func (helper *LeagueHelper) participantRunes(p *ParticipantDTO) string {
+    if p.Perks == nil || len(p.Perks.Styles) == 0 {
+        return "None"
+    }
+    var keystone string
+    var trees []string
+    for _, style := range(p.Perks.Styles) {
+        if style.Description == "primaryStyle" && len(style.Selections) > 0 {
+            keystone = helper.getRuneName(style.Selections[0].Perk)
+        }
+        if name := helper.getRuneName(style.Style); name != "" {
+            trees = append(trees, name)
+        }
+    }
+    if keystone == "" {
+        return strings.Join(trees, "/")
+    }
+    return fmt.Sprintf("%v (%v)", keystone, strings.Join(trees, "/"))
+}
+
+func (helper *LeagueHelper) participantItems(p *ParticipantDTO) string {
+    var items []string
+    for _, id := range(p.Items()) {
+        if id == 0 {
+            continue
+        }
+        name := helper.getItemName(id)
+        if name == "" {
+            name = fmt.Sprintf("Item %v", id)
+        }
+        items = append(items, name)
+    }
+    if len(items) == 0 {
+        return "None"
+    }
+    return strings.Join(items, ", ")
+}
+
+func objectiveKills(o *ObjectiveDTO) int {
+    if o == nil {
+        return 0
+    }
+    return o.Kills
+}
+
+// e.g. "Victory · 32 kills · 9 towers · 2 inhibitors · 3 dragons · 1 baron · 1 herald"
+func teamSummary(t *MatchTeamDTO) string {
+    result := "Defeat"
+    if t.Win {
+        result = "Victory"
+    }
+    o := t.Objectives
+    if o == nil {
+        return result
+    }
+    return fmt.Sprintf("%v · %v kills · %v towers · %v inhibitors · %v dragons · %v barons · %v heralds",
+        result, objectiveKills(o.Champion), objectiveKills(o.Tower), objectiveKills(o.Inhibitor),
+        objectiveKills(o.Dragon), objectiveKills(o.Baron), objectiveKills(o.RiftHerald))
+}
+
+func (helper *LeagueHelper) GetMatchEmbed(region *LeagueRegion, matchID string) *discordgo.MessageEmbed {
+    ctx, cancel := context.WithTimeout(context.Background(), RiotTimeout)
+    defer cancel()
+    embed := &discordgo.MessageEmbed{}
+
+    matchID, region = parseMatchID(matchID, region)
+    match, err := helper.GetMatch(ctx, region, matchID)
+    if err != nil {
+        return riotErrorEmbed("GetMatchEmbed", err, "Match " + matchID)
+    }
+    if match.Info == nil {
+        return MakeErrorEmbed("Match " + matchID + " has no data.")
+    }
+
+    helper.Lock.RLock()
+    defer helper.Lock.RUnlock()
+
+    d := match.Info.Duration()
+    embed.Color = 0xD13739
+    embed.Title = fmt.Sprintf("Match %v", matchID)
+    embed.Description = fmt.Sprintf("%v · %v · <t:%v:f>", QueueName(match.Info.QueueID), formatGameDuration(d), match.Info.Started().Unix())
+    embed.Footer = &discordgo.MessageEmbedFooter{
+        Text: region.String(),
+    }
+
+    for _, team := range([]struct{ id int; name string }{ { BlueTeam, "Blue Team" }, { RedTeam, "Red Team" } }) {
+        for _, t := range(match.Info.Teams) {
+            if t.TeamID == team.id {
+                embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+                    Name: "__" + team.name + "__",
+                    Value: teamSummary(t),
+                })
+            }
+        }
+        for _, p := range(match.Info.Participants) {
+            if p.TeamID != team.id {
+                continue
+            }
+            embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+                Name: fmt.Sprintf("%v · %v", helper.participantChampion(p), p.Name()),
+                Value: fmt.Sprintf("%v/%v/%v · %v CS · %v gold · %v damage · %v vision\n%v\n%v",
+                    p.Kills, p.Deaths, p.Assists, p.CS(), shortNumber(p.GoldEarned),
+                    shortNumber(p.TotalDamageDealtToChampions), p.VisionScore,
+                    helper.participantItems(p), helper.participantRunes(p)),
+            })
+        }
+    }
+    // arena has more than two teams, and discord only allows 25 fields
+    if len(embed.Fields) > 25 {
+        embed.Fields = embed.Fields[:25]
+    }
+
+    return embed
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "strings"
+)
+
+// the queues shown in profiles, in order
+var RankedQueues = []struct{
+    Type    string
+    Name    string
+}{
+    { "RANKED_SOLO_5x5", "Ranked Solo/Duo" },
+    { "RANKED_FLEX_SR", "Ranked Flex" },
+}
+
+// lowest first
+var RankTiers = []string{ "IRON", "BRONZE", "SILVER", "GOLD", "PLATINUM", "EMERALD", "DIAMOND", "MASTER", "GRANDMASTER", "CHALLENGER" }
+
+// lowest first
+var rankDivisions = []string{ "IV", "III", "II", "I" }
+
+// master and above don't have divisions
+var apexTiers = map[string]bool {
+    "MASTER": true,
+    "GRANDMASTER": true,
+    "CHALLENGER": true,
+}
+
+func (helper *LeagueHelper) GetLeagueEntries(ctx context.Context, region *LeagueRegion, puuid string) (LeagueEntries, error) {
+    var entries LeagueEntries
+    err := helper.Client.Get(ctx, region.Platform, LEAGUE_ENTRIES, &entries, puuid)
+    if err != nil {
+        return nil, err
+    }
+    return entries, nil
+}
+
+// returns nil if they aren't ranked in that queue
+func (entries LeagueEntries) Queue(queueType string) *LeagueEntryDTO {
+    for _, e := range(entries) {
+        if e.QueueType == queueType {
+            return e
+        }
+    }
+    return nil
+}
+
+// solo/duo if they have it, then flex; nil if they're unranked in both
+func (entries LeagueEntries) Best() *LeagueEntryDTO {
+    for _, queue := range(RankedQueues) {
+        if e := entries.Queue(queue.Type); e != nil {
+            return e
+        }
+    }
+    return nil
+}
+
+// e.g. "Gold II" or "Challenger"
+func (e *LeagueEntryDTO) TierString() string {
+    tier := strings.Title(strings.ToLower(e.Tier))
+    if apexTiers[e.Tier] {
+        return tier
+    }
+    return tier + " " + e.Rank
+}
+
+// for sorting; higher is better. each division is worth 100 LP, and each tier 400.
+// master and above are all sorted by LP, since that's what decides which of them you're in.
+func (e *LeagueEntryDTO) Score() int {
+    tier := e.Tier
+    if apexTiers[tier] {
+        tier = "MASTER"
+    }
+    score := -1
+    for i, t := range(RankTiers) {
+        if t == tier {
+            score = i * 400
+        }
+    }
+    if score < 0 {
+        return 0
+    }
+    for i, d := range(rankDivisions) {
+        if d == e.Rank && !apexTiers[e.Tier] {
+            score += i * 100
+        }
+    }
+    return score + e.LeaguePoints
+}
+
+func (e *LeagueEntryDTO) EmblemURL() string {
+    return fmt.Sprintf(RANK_EMBLEM, strings.ToLower(e.Tier))
+}
+
+func (e *LeagueEntryDTO) WinRate() float64 {
+    if e.Wins + e.Losses == 0 {
+        return 0
+    }
+    return float64(e.Wins) / float64(e.Wins + e.Losses) * 100
+}
+
+// everything about one queue, for an embed field
+func (e *LeagueEntryDTO) Summary() string {
+    s := fmt.Sprintf("**%v** %v LP\n%vW %vL (%.1f%%)", e.TierString(), e.LeaguePoints, e.Wins, e.Losses, e.WinRate())
+    if e.HotStreak {
+        s += "\n🔥 On a hot streak"
+    }
+    if e.MiniSeries != nil {
+        s += "\nSeries: " + seriesProgress(e.MiniSeries.Progress)
+    }
+    return s
+}
+
+// turns "WLN" into emoji
+func seriesProgress(progress string) string {
+    var games []string
+    for _, g := range(progress) {
+        switch g {
+            case 'W':
+                games = append(games, "✅")
+            case 'L':
+                games = append(games, "❌")
+            default:
+                games = append(games, "➖")
+        }
+    }
+    return strings.Join(games, " ")
+}
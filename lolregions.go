@@ -0,0 +1,88 @@
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// used when neither the command nor the guild picks a region
+const DefaultLeagueRegion = "na"
+
+// riot splits its API between platform hosts (one per server, like euw1) for summoners,
+// masteries and status, and regional hosts (one per continent) for things like matches
+type LeagueRegion struct {
+    Name        string // what people type, like "euw"
+    Platform    string // like "euw1"
+    Regional    string // like "europe"
+    Tag         string // the tag line riot gave everyone on this server, used for Riot IDs without one
+}
+
+var LeagueRegions = map[string]*LeagueRegion {
+    "br": { "br", "br1", "americas", "BR1" },
+    "eune": { "eune", "eun1", "europe", "EUNE" },
+    "euw": { "euw", "euw1", "europe", "EUW" },
+    "jp": { "jp", "jp1", "asia", "JP1" },
+    "kr": { "kr", "kr", "asia", "KR1" },
+    "lan": { "lan", "la1", "americas", "LAN" },
+    "las": { "las", "la2", "americas", "LAS" },
+    "na": { "na", "na1", "americas", "NA1" },
+    "oce": { "oce", "oc1", "sea", "OCE" },
+    "ph": { "ph", "ph2", "sea", "PH2" },
+    "ru": { "ru", "ru", "europe", "RU" },
+    "sg": { "sg", "sg2", "sea", "SG2" },
+    "th": { "th", "th2", "sea", "TH2" },
+    "tr": { "tr", "tr1", "europe", "TR1" },
+    "tw": { "tw", "tw2", "sea", "TW2" },
+    "vn": { "vn", "vn2", "sea", "VN2" },
+}
+
+// accepts either the short name or the platform, like "euw" or "euw1". returns nil if there's no such region
+func FindLeagueRegion(name string) *LeagueRegion {
+    name = strings.ToLower(strings.TrimSpace(name))
+    if r, ok := LeagueRegions[name]; ok {
+        return r
+    }
+    for _, r := range(LeagueRegions) {
+        if r.Platform == name {
+            return r
+        }
+    }
+    return nil
+}
+
+// sorted, for help messages and slash command choices
+func LeagueRegionNames() []string {
+    names := make([]string, 0, len(LeagueRegions))
+    for name := range(LeagueRegions) {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// the regional host for account-v1, which has no "sea" host
+func (r *LeagueRegion) AccountHost() string {
+    if r.Regional == "sea" {
+        return "asia"
+    }
+    return r.Regional
+}
+
+// e.g. "EUW (euw1)"
+func (r *LeagueRegion) String() string {
+    return fmt.Sprintf("%v (%v)", strings.ToUpper(r.Name), r.Platform)
+}
+
+// the region named by a command, or else the guild's default, or else DefaultLeagueRegion
+func (helper *LeagueHelper) Region(guildID, name string) *LeagueRegion {
+    if r := FindLeagueRegion(name); r != nil {
+        return r
+    }
+    if guildID != "" {
+        if r := FindLeagueRegion(GetGuildSettings(guildID).LeagueRegion); r != nil {
+            return r
+        }
+    }
+    return LeagueRegions[DefaultLeagueRegion]
+}
@@ -4,12 +4,15 @@ import "sync"
 
 // use this to access all the data about league
 type LeagueHelper struct {
-    Token   string
+    Client  *RiotClient
     Version string
 
-    ChampionData *ChampionFile // current champion data file's contents
+    ChampionData        *ChampionFile // current champion data file's contents
+    ItemData            *ItemFile // nil if item.json couldn't be loaded
+    RuneTrees           []*RuneTreeDTO // from runesReforged.json
+    SummonerSpellData   *SummonerSpellFile // nil if summoner.json couldn't be loaded
 
-    Lock sync.Mutex // used to lock this struct while updating it
+    Lock sync.RWMutex // guards the data dragon fields above: RLock to read them, Lock to update them
 }
 
 /* Data Types */
@@ -19,16 +22,21 @@ type LeagueStatus struct {
     Message     string  `json:"message,omitempty"`
 }
 
+// account-v1; one per Riot ID, across every region
+type RiotAccount struct {
+    PUUID       string  `json:"puuid,omitempty"`
+    GameName    string  `json:"gameName,omitempty"`
+    TagLine     string  `json:"tagLine,omitempty"`
+}
+
 type Summoner struct {
     ProfileIconID   int     `json:"profileIconId,omitempty"`
-    Name            string  `json:"name,omitempty"`
+    Name            string  `json:"name,omitempty"` // filled in with their Riot ID by GetSummoner
     PUUID           string  `json:"puuid,omitempty"`
     Level           int64   `json:"summonerLevel,omitempty"`
     RevisionDate    int64   `json:"revisionDate,omitempty"`
     ID              string  `json:"id,omitempty"`
     AccountID       string  `json:"accountId,omitempty"`
-    
-    Status  *LeagueStatus   `json:"status,omitempty"`
 }
 
 type ChampionMasteries []ChampionMasteryDTO
@@ -43,8 +51,154 @@ type ChampionMasteryDTO struct {
     TokensEarned            int     `json:"tokensEarned,omitempty"`
     PointsSinceLastLevel    int64   `json:"championPointsSinceLastLevel,omitempty"`
     SummonerID              string  `json:"summonerId,omitempty"`
+}
+
+type LeagueEntries []*LeagueEntryDTO
+
+// from league-v4
+type LeagueEntryDTO struct {
+    LeagueID        string          `json:"leagueId,omitempty"`
+    QueueType       string          `json:"queueType,omitempty"`
+    Tier            string          `json:"tier,omitempty"`
+    Rank            string          `json:"rank,omitempty"`
+    SummonerID      string          `json:"summonerId,omitempty"`
+    SummonerName    string          `json:"summonerName,omitempty"`
+    LeaguePoints    int             `json:"leaguePoints"`
+    Wins            int             `json:"wins"`
+    Losses          int             `json:"losses"`
+    HotStreak       bool            `json:"hotStreak,omitempty"`
+    Veteran         bool            `json:"veteran,omitempty"`
+    FreshBlood      bool            `json:"freshBlood,omitempty"`
+    Inactive        bool            `json:"inactive,omitempty"`
+    MiniSeries      *MiniSeriesDTO  `json:"miniSeries,omitempty"`
+}
+
+// promotion series
+type MiniSeriesDTO struct {
+    Losses      int     `json:"losses"`
+    Wins        int     `json:"wins"`
+    Target      int     `json:"target"`
+    Progress    string  `json:"progress,omitempty"` // like "WLN", N being games not played yet
+}
+
+// from match-v5
+type MatchDTO struct {
+    Metadata    *MatchMetadataDTO   `json:"metadata,omitempty"`
+    Info        *MatchInfoDTO       `json:"info,omitempty"`
+}
 
-    Status  *LeagueStatus   `json:"status,omitempty"`
+type MatchMetadataDTO struct {
+    MatchID         string      `json:"matchId,omitempty"`
+    Participants    []string    `json:"participants,omitempty"` // PUUIDs
+}
+
+type MatchInfoDTO struct {
+    GameCreation        int64               `json:"gameCreation,omitempty"`
+    GameDuration        int64               `json:"gameDuration,omitempty"` // seconds if GameEndTimestamp is set, otherwise milliseconds
+    GameEndTimestamp    int64               `json:"gameEndTimestamp,omitempty"`
+    GameStartTimestamp  int64               `json:"gameStartTimestamp,omitempty"`
+    GameMode            string              `json:"gameMode,omitempty"`
+    GameVersion         string              `json:"gameVersion,omitempty"`
+    MapID               int                 `json:"mapId,omitempty"`
+    PlatformID          string              `json:"platformId,omitempty"`
+    QueueID             int                 `json:"queueId"`
+    Participants        []*ParticipantDTO   `json:"participants,omitempty"`
+    Teams               []*MatchTeamDTO     `json:"teams,omitempty"`
+}
+
+type MatchTeamDTO struct {
+    TeamID      int             `json:"teamId,omitempty"`
+    Win         bool            `json:"win"`
+    Objectives  *ObjectivesDTO  `json:"objectives,omitempty"`
+}
+
+type ObjectivesDTO struct {
+    Baron       *ObjectiveDTO   `json:"baron,omitempty"`
+    Champion    *ObjectiveDTO   `json:"champion,omitempty"`
+    Dragon      *ObjectiveDTO   `json:"dragon,omitempty"`
+    Inhibitor   *ObjectiveDTO   `json:"inhibitor,omitempty"`
+    RiftHerald  *ObjectiveDTO   `json:"riftHerald,omitempty"`
+    Tower       *ObjectiveDTO   `json:"tower,omitempty"`
+}
+
+type ObjectiveDTO struct {
+    First   bool    `json:"first"`
+    Kills   int     `json:"kills"`
+}
+
+type ParticipantDTO struct {
+    PUUID                       string  `json:"puuid,omitempty"`
+    SummonerName                string  `json:"summonerName,omitempty"`
+    SummonerID                  string  `json:"summonerId,omitempty"`
+    RiotIDGameName              string  `json:"riotIdGameName,omitempty"`
+    RiotIDTagline               string  `json:"riotIdTagline,omitempty"`
+    ChampionID                  int     `json:"championId,omitempty"`
+    ChampionName                string  `json:"championName,omitempty"`
+    ChampLevel                  int     `json:"champLevel,omitempty"`
+    TeamID                      int     `json:"teamId,omitempty"`
+    TeamPosition                string  `json:"teamPosition,omitempty"`
+    Kills                       int     `json:"kills"`
+    Deaths                      int     `json:"deaths"`
+    Assists                     int     `json:"assists"`
+    TotalMinionsKilled          int     `json:"totalMinionsKilled"`
+    NeutralMinionsKilled        int     `json:"neutralMinionsKilled"`
+    GoldEarned                  int     `json:"goldEarned"`
+    TotalDamageDealtToChampions int     `json:"totalDamageDealtToChampions"`
+    VisionScore                 int     `json:"visionScore"`
+    Win                         bool    `json:"win"`
+    GameEndedInEarlySurrender   bool    `json:"gameEndedInEarlySurrender,omitempty"`
+    Summoner1ID                 int     `json:"summoner1Id,omitempty"`
+    Summoner2ID                 int     `json:"summoner2Id,omitempty"`
+    Item0                       int     `json:"item0"`
+    Item1                       int     `json:"item1"`
+    Item2                       int     `json:"item2"`
+    Item3                       int     `json:"item3"`
+    Item4                       int     `json:"item4"`
+    Item5                       int     `json:"item5"`
+    Item6                       int     `json:"item6"` // the trinket
+
+    Perks   *PerksDTO   `json:"perks,omitempty"`
+}
+
+type PerksDTO struct {
+    Styles  []*PerkStyleDTO `json:"styles,omitempty"`
+}
+
+// Description is "primaryStyle" or "subStyle"
+type PerkStyleDTO struct {
+    Description string                      `json:"description,omitempty"`
+    Style       int                         `json:"style,omitempty"`
+    Selections  []*PerkStyleSelectionDTO    `json:"selections,omitempty"`
+}
+
+type PerkStyleSelectionDTO struct {
+    Perk    int     `json:"perk,omitempty"`
+}
+
+// from spectator-v4
+type CurrentGameInfo struct {
+    GameID              int64                       `json:"gameId,omitempty"`
+    GameType            string                      `json:"gameType,omitempty"`
+    GameStartTime       int64                       `json:"gameStartTime,omitempty"` // 0 while people are still loading
+    MapID               int                         `json:"mapId,omitempty"`
+    GameLength          int64                       `json:"gameLength,omitempty"` // seconds
+    PlatformID          string                      `json:"platformId,omitempty"`
+    GameMode            string                      `json:"gameMode,omitempty"`
+    GameQueueConfigID   int                         `json:"gameQueueConfigId"`
+    Participants        []*CurrentGameParticipant   `json:"participants,omitempty"`
+}
+
+type CurrentGameParticipant struct {
+    ChampionID      int     `json:"championId,omitempty"`
+    ProfileIconID   int     `json:"profileIconId,omitempty"`
+    Bot             bool    `json:"bot,omitempty"`
+    TeamID          int     `json:"teamId,omitempty"`
+    SummonerName    string  `json:"summonerName,omitempty"`
+    SummonerID      string  `json:"summonerId,omitempty"`
+    PUUID           string  `json:"puuid,omitempty"`
+    RiotID          string  `json:"riotId,omitempty"` // like Some Name#TAG
+    Spell1ID        int     `json:"spell1Id,omitempty"`
+    Spell2ID        int     `json:"spell2Id,omitempty"`
 }
 
 type GenericLeagueError struct {
@@ -118,6 +272,73 @@ type ChampionStatsDTO struct {
     AttackSpeedPerLevel     float32 `json:"attackspeedperlevel,omitempty"`
 }
 
+// item.json
+type ItemFile struct {
+    Version string  `json:"version,omitempty"`
+
+    Data    map[string]*ItemDTO `json:"data,omitempty"` // by item ID
+}
+
+type ItemDTO struct {
+    Name        string      `json:"name,omitempty"`
+    Description string      `json:"description,omitempty"`
+    Plaintext   string      `json:"plaintext,omitempty"`
+    From        []string    `json:"from,omitempty"`
+    Into        []string    `json:"into,omitempty"`
+    Tags        []string    `json:"tags,omitempty"`
+
+    Gold    *ItemGoldDTO        `json:"gold,omitempty"`
+    Image   *ImageDTO           `json:"image,omitempty"`
+    Maps    map[string]bool     `json:"maps,omitempty"` // by map ID; 11 is summoner's rift
+    Stats   map[string]float64  `json:"stats,omitempty"`
+}
+
+type ItemGoldDTO struct {
+    Base        int     `json:"base"`
+    Total       int     `json:"total"`
+    Sell        int     `json:"sell"`
+    Purchasable bool    `json:"purchasable"`
+}
+
+// summoner.json
+type SummonerSpellFile struct {
+    Version string  `json:"version,omitempty"`
+
+    Data    map[string]*SummonerSpellDTO `json:"data,omitempty"`
+}
+
+type SummonerSpellDTO struct {
+    ID          string      `json:"id,omitempty"`
+    Key         string      `json:"key,omitempty"` // the numeric ID used in matches
+    Name        string      `json:"name,omitempty"`
+    Description string      `json:"description,omitempty"`
+    Cooldown    []float64   `json:"cooldown,omitempty"`
+
+    Image   *ImageDTO   `json:"image,omitempty"`
+}
+
+// runesReforged.json is a list of these
+type RuneTreeDTO struct {
+    ID      int             `json:"id,omitempty"`
+    Key     string          `json:"key,omitempty"`
+    Icon    string          `json:"icon,omitempty"`
+    Name    string          `json:"name,omitempty"`
+    Slots   []*RuneSlotDTO  `json:"slots,omitempty"`
+}
+
+type RuneSlotDTO struct {
+    Runes   []*RuneDTO  `json:"runes,omitempty"`
+}
+
+type RuneDTO struct {
+    ID          int     `json:"id,omitempty"`
+    Key         string  `json:"key,omitempty"`
+    Icon        string  `json:"icon,omitempty"`
+    Name        string  `json:"name,omitempty"`
+    ShortDesc   string  `json:"shortDesc,omitempty"`
+    LongDesc    string  `json:"longDesc,omitempty"`
+}
+
 type ChampionSpellDTO struct {
     ID          string  `json:"id,omitempty"`
     Name        string  `json:"name,omitempty"`
@@ -139,46 +360,39 @@ type ChampionPassiveDTO struct {
     Image   *ImageDTO   `json:"image,omitempty"`
 }
 
-type ServerStatus struct {
-    Name        string      `json:"name,omitempty"`
-    RegionTag   string      `json:"region_tag,omitempty"`
-    Hostname    string      `json:"hostname,omitempty"`
-    Slug        string      `json:"slug,omitempty"`
-    Locales     []string    `json:"locales,omitempty"`
-
-    Services    []*Service  `json:"services,omitempty"`
-    Status  *LeagueStatus   `json:"status,omitempty"`
-}
-
-type Service struct {
-    Status  string  `json:"status,omitempty"`
-    Name    string  `json:"name,omitempty"`
-    Slug    string  `json:"slug,omitempty"`
-
-    Incidents   []*Incident `json:"incidents,omitempty"`
+// from lol-status-v4
+type PlatformStatus struct {
+    ID              string          `json:"id,omitempty"`
+    Name            string          `json:"name,omitempty"`
+    Locales         []string        `json:"locales,omitempty"`
+    Maintenances    []*StatusEntry  `json:"maintenances,omitempty"`
+    Incidents       []*StatusEntry  `json:"incidents,omitempty"`
 }
 
-type Incident struct {
-    Active      bool    `json:"active,omitempty"`
-    CreatedAt   string  `json:"created_at,omitempty"`
-    ID          uint64  `json:"id,omitempty"`
-
-    Updates []*Message  `json:"updates,omitempty"`
+// a single maintenance or incident
+type StatusEntry struct {
+    ID                  int64               `json:"id,omitempty"`
+    MaintenanceStatus   string              `json:"maintenance_status,omitempty"`
+    IncidentSeverity    string              `json:"incident_severity,omitempty"`
+    Titles              []*StatusContent    `json:"titles,omitempty"`
+    Updates             []*StatusUpdate     `json:"updates,omitempty"`
+    CreatedAt           string              `json:"created_at,omitempty"`
+    ArchiveAt           string              `json:"archive_at,omitempty"`
+    UpdatedAt           string              `json:"updated_at,omitempty"`
+    Platforms           []string            `json:"platforms,omitempty"`
 }
 
-type Message struct {
-    Severity    string  `json:"severity,omitempty"`
-    Author      string  `json:"author,omitempty"`
-    CreatedAt   string  `json:"created_at,omitempty"`
-    UpdatedAt   string  `json:"updated_at,omitempty"`
-    Content     string  `json:"content,omitempty"`
-    ID          string  `json:"id,omitempty"`
-
-    Translations    []*Translation  `json:"translations,omitempty"`
+type StatusUpdate struct {
+    ID                  int64               `json:"id,omitempty"`
+    Author              string              `json:"author,omitempty"`
+    Publish             bool                `json:"publish,omitempty"`
+    PublishLocations    []string            `json:"publish_locations,omitempty"`
+    Translations        []*StatusContent    `json:"translations,omitempty"`
+    CreatedAt           string              `json:"created_at,omitempty"`
+    UpdatedAt           string              `json:"updated_at,omitempty"`
 }
 
-type Translation struct {
+type StatusContent struct {
     Locale      string  `json:"locale,omitempty"`
     Content     string  `json:"content,omitempty"`
-    UpdatedAt   string  `json:"updated_at,omitempty"`
 }
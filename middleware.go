@@ -0,0 +1,248 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "math"
+    "runtime/debug"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// every command goes through these, outermost first
+var DefaultMiddleware = []Middleware {
+    MetricsMiddleware,
+    LoggingMiddleware,
+    RecoverMiddleware,
+    ChannelTypeMiddleware,
+    LeagueMiddleware,
+    AdminMiddleware,
+    PermissionMiddleware,
+    CooldownMiddleware,
+    TypingMiddleware,
+}
+
+// wraps the handler in DefaultMiddleware and then the command's own Middleware
+func (cmd *Command) Pipeline() CommandFunc {
+    chain := cmd.invoke
+    for i := len(cmd.Middleware) - 1; i >= 0; i-- {
+        chain = cmd.Middleware[i](chain)
+    }
+    for i := len(DefaultMiddleware) - 1; i >= 0; i-- {
+        chain = DefaultMiddleware[i](chain)
+    }
+    return chain
+}
+
+// the end of the chain: parse the arguments and run the handler
+func (cmd *Command) invoke(ctx *CommandContext) {
+    var args *CommandArgs
+    var err error
+    if ctx.IsInteraction() {
+        args, err = cmd.ArgsFromOptions(ctx.options)
+    } else {
+        args, err = cmd.ParseArgs(ctx.argtext)
+    }
+
+    if err != nil {
+        _, err = ctx.ReplyEmbed(cmd.UsageEmbed(ctx, err))
+        if err != nil {
+            log.Printf("Error in Command.invoke:\n%v\n", err)
+        }
+        return
+    }
+
+    cmd.Handler(ctx, ctx.Session, args)
+}
+
+// lets the user know a command was refused. text commands that aren't meant for the user
+// (quiet) are ignored entirely, so nobody learns that they exist
+func refuse(ctx *CommandContext, reason string, quiet bool) {
+    if quiet && !ctx.IsInteraction() {
+        return
+    }
+    err := ctx.ReplyPrivate(reason)
+    if err != nil {
+        log.Printf("Error in refuse:\n%v\n", err)
+    }
+}
+
+// a panicking handler gets reported instead of crashing the bot, and the user gets a generic error
+func RecoverMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        defer func() {
+            if r := recover(); r != nil {
+                ctx.Failed = true
+                ReportPanic(ctx.Session, r, debug.Stack(), "command " + ctx.Command.Name, ctx.Trigger())
+                _, err := ctx.Reply("Something went wrong, please try again later. Sorry! :(")
+                if err != nil {
+                    log.Printf("Error in RecoverMiddleware:\n%v\n", err)
+                }
+            }
+        }()
+        next(ctx)
+    }
+}
+
+func LoggingMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        start := time.Now()
+        next(ctx)
+
+        where := "a DM"
+        if ctx.GuildID != "" {
+            where = "guild " + ctx.GuildID
+        }
+        status := ""
+        if ctx.Failed {
+            status = " and failed"
+        }
+        log.Printf("%v (%v) used %v in %v; took %v%v\n", ctx.Author.String(), ctx.Author.ID, ctx.Command.Name, where, time.Since(start), status)
+    }
+}
+
+func ChannelTypeMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        if ctx.Command.GuildOnly && ctx.GuildID == "" {
+            refuse(ctx, "Sorry, that command only works in servers.", false)
+            return
+        }
+        if ctx.Command.DMOnly && ctx.GuildID != "" {
+            refuse(ctx, "Sorry, that command only works in DMs.", false)
+            return
+        }
+        next(ctx)
+    }
+}
+
+// league commands are turned off when there's no API key or data, so none of their handlers have to check
+func LeagueMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        if !EnableLOL && ctx.Command.Category == "lol" {
+            refuse(ctx, "Sorry, but League commands are disabled due to a configuration issue. Check back later.", false)
+            return
+        }
+        next(ctx)
+    }
+}
+
+func AdminMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        if ctx.Command.AdminOnly && !ctx.IsBotAdmin() {
+            refuse(ctx, "Sorry, only bot admins can use that command.", true)
+            return
+        }
+        next(ctx)
+    }
+}
+
+func PermissionMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        if !ctx.CanUse(ctx.Command) {
+            reason := "Sorry, you aren't allowed to use that command here."
+            if ctx.Command.Permissions != 0 {
+                reason += fmt.Sprintf(" It needs the %v permission.", PermissionString(ctx.Command.Permissions))
+            }
+            refuse(ctx, reason, false)
+            return
+        }
+        next(ctx)
+    }
+}
+
+func TypingMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        // slash commands have to be acknowledged quickly whether they "type" or not
+        if !ctx.Command.NoTyping || ctx.IsInteraction() {
+            ctx.Typing()
+        }
+        next(ctx)
+    }
+}
+
+/* Cooldowns */
+
+func CooldownMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        if len(ctx.Command.Cooldowns) > 0 && !Config.IsAdmin(ctx.Author.ID) {
+            wait := Limiter.Take(ctx, ctx.Command.Cooldowns)
+            if wait > 0 {
+                // round up, so nobody is told to wait 0s
+                secs := int(math.Ceil(wait.Seconds()))
+                refuse(ctx, fmt.Sprintf("Slow down! You can use `%v` again in %vs.", ctx.Command.Name, secs), false)
+                return
+            }
+        }
+        next(ctx)
+    }
+}
+
+/* Metrics */
+
+type CommandMetrics struct {
+    Uses        int
+    Failures    int
+    TotalTime   time.Duration
+}
+
+var commandMetrics = make(map[string]*CommandMetrics)
+var metricsLock sync.Mutex
+
+func MetricsMiddleware(next CommandFunc) CommandFunc {
+    return func(ctx *CommandContext) {
+        start := time.Now()
+        next(ctx)
+        elapsed := time.Since(start)
+
+        metricsLock.Lock()
+        defer metricsLock.Unlock()
+        m, ok := commandMetrics[ctx.Command.Name]
+        if !ok {
+            m = &CommandMetrics{}
+            commandMetrics[ctx.Command.Name] = m
+        }
+        m.Uses++
+        m.TotalTime += elapsed
+        if ctx.Failed {
+            m.Failures++
+        }
+    }
+}
+
+// most-used commands first
+func MetricsEmbed() *discordgo.MessageEmbed {
+    metricsLock.Lock()
+    defer metricsLock.Unlock()
+
+    names := make([]string, 0, len(commandMetrics))
+    for name := range(commandMetrics) {
+        names = append(names, name)
+    }
+    sort.Slice(names, func(i, j int) bool {
+        return commandMetrics[names[i]].Uses > commandMetrics[names[j]].Uses
+    })
+
+    embed := &discordgo.MessageEmbed{
+        Title: "Command Metrics",
+        Description: "Since the bot last started.",
+    }
+    if len(names) == 0 {
+        embed.Description += "\nNo commands have been used yet."
+    }
+    for _, name := range(names) {
+        m := commandMetrics[name]
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: name,
+            Value: fmt.Sprintf("%v uses, %v failures, %v average", m.Uses, m.Failures, (m.TotalTime / time.Duration(m.Uses)).Round(time.Millisecond)),
+            Inline: true,
+        })
+    }
+    // discord only allows 25 fields
+    if len(embed.Fields) > 25 {
+        embed.Fields = embed.Fields[:25]
+    }
+    return embed
+}
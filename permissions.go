@@ -0,0 +1,134 @@
+package main
+
+import (
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// per-command role lists for one guild
+type RoleRules struct {
+    Allow   []string    `json:",omitempty"` // these roles can use the command even without its Permissions
+    Deny    []string    `json:",omitempty"` // these roles can never use the command
+}
+
+// for showing Command.Permissions to people
+var permissionNames = []struct{
+    Perm int64
+    Name string
+}{
+    { discordgo.PermissionAdministrator, "Administrator" },
+    { discordgo.PermissionManageServer, "Manage Server" },
+    { discordgo.PermissionManageChannels, "Manage Channels" },
+    { discordgo.PermissionManageRoles, "Manage Roles" },
+    { discordgo.PermissionManageMessages, "Manage Messages" },
+    { discordgo.PermissionKickMembers, "Kick Members" },
+    { discordgo.PermissionBanMembers, "Ban Members" },
+    { discordgo.PermissionSendTTSMessages, "Send TTS Messages" },
+    { discordgo.PermissionMentionEveryone, "Mention Everyone" },
+    { discordgo.PermissionViewChannel, "View Channel" },
+    { discordgo.PermissionSendMessages, "Send Messages" },
+    { discordgo.PermissionEmbedLinks, "Embed Links" },
+}
+
+func PermissionString(perms int64) string {
+    var names []string
+    for _, p := range(permissionNames) {
+        if perms & p.Perm == p.Perm {
+            names = append(names, p.Name)
+        }
+    }
+    return strings.Join(names, ", ")
+}
+
+func (ctx *CommandContext) IsBotAdmin() bool {
+    return Config.IsAdmin(ctx.Author.ID) || ctx.Author.ID == Config.ControllerID
+}
+
+// the author's role IDs in the current guild
+func (ctx *CommandContext) AuthorRoles() []string {
+    if ctx.IsInteraction() {
+        if ctx.Interaction.Member != nil {
+            return ctx.Interaction.Member.Roles
+        }
+    } else if ctx.Message.Member != nil {
+        return ctx.Message.Member.Roles
+    }
+    return nil
+}
+
+// true for bot admins, and for anyone with all of perms in the current channel
+func (ctx *CommandContext) HasPermissions(perms int64) bool {
+    if ctx.IsBotAdmin() {
+        return true
+    }
+    if perms == 0 {
+        return true
+    }
+    actual, err := ctx.AuthorPermissions()
+    if err != nil {
+        return false
+    }
+    return actual & perms == perms
+}
+
+func hasAnyRole(roles, of []string) bool {
+    for _, r := range(roles) {
+        for _, o := range(of) {
+            if r == o {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// checks, in order: bot admins can use anything, denied roles can't use it, allowed roles can.
+// if the guild has an allow list, only those roles (and server managers) can use it;
+// otherwise the command's Permissions decide.
+func (ctx *CommandContext) CanUse(cmd *Command) bool {
+    if ctx.IsBotAdmin() {
+        return true
+    }
+    if ctx.GuildID == "" {
+        // there are no guild permissions or roles in DMs
+        return cmd.Permissions == 0
+    }
+
+    rules := GetGuildSettings(ctx.GuildID).Roles[cmd.Name]
+    if rules != nil {
+        roles := ctx.AuthorRoles()
+        if hasAnyRole(roles, rules.Deny) {
+            return false
+        }
+        if hasAnyRole(roles, rules.Allow) {
+            return true
+        }
+        if len(rules.Allow) > 0 {
+            return ctx.HasPermissions(discordgo.PermissionManageServer)
+        }
+    }
+
+    return ctx.HasPermissions(cmd.Permissions)
+}
+
+// adds role to one list and takes it out of the other
+func (rr *RoleRules) Set(role string, allow bool) {
+    rr.Allow = removeString(rr.Allow, role)
+    rr.Deny = removeString(rr.Deny, role)
+    if allow {
+        rr.Allow = append(rr.Allow, role)
+    } else {
+        rr.Deny = append(rr.Deny, role)
+    }
+}
+
+func removeString(list []string, s string) []string {
+    var out []string
+    for _, l := range(list) {
+        if l != s {
+            out = append(out, l)
+        }
+    }
+    return out
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// used when Config.Prefixes is empty
+var DefaultPrefixes = []string{ "cactus", "c" }
+
+const MaxPrefixLength = 10
+
+func Prefixes() []string {
+    if len(Config.Prefixes) == 0 {
+        return DefaultPrefixes
+    }
+    return Config.Prefixes
+}
+
+// if the prefix ends in a letter or number it has to be followed by whitespace, so "c" doesn't eat "coinflip".
+// returns the rest of content with leading whitespace removed
+func matchPrefix(content, prefix string) (string, bool) {
+    if prefix == "" || len(content) < len(prefix) || !strings.EqualFold(content[:len(prefix)], prefix) {
+        return "", false
+    }
+
+    rest := content[len(prefix):]
+    trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace)
+
+    last, _ := utf8.DecodeLastRuneInString(prefix)
+    if (unicode.IsLetter(last) || unicode.IsNumber(last)) && len(trimmed) == len(rest) && rest != "" {
+        return "", false
+    }
+
+    return trimmed, true
+}
+
+// the prefix as it should be shown to users, e.g. "c " or "!"
+func displayPrefix(prefix string) string {
+    last, _ := utf8.DecodeLastRuneInString(prefix)
+    if unicode.IsLetter(last) || unicode.IsNumber(last) {
+        return prefix + " "
+    }
+    return prefix
+}
+
+// the default prefixes as users should read them, e.g. DefaultPrefixList("or") is "`cactus` or `c`"
+func DefaultPrefixList(and string) string {
+    var quoted []string
+    for _, p := range(Prefixes()) {
+        quoted = append(quoted, "`" + strings.TrimSpace(p) + "`")
+    }
+    if len(quoted) == 1 {
+        return quoted[0]
+    }
+    return strings.Join(quoted[:len(quoted)-1], ", ") + " " + and + " " + quoted[len(quoted)-1]
+}
+
+// the prefix users should be told to use in this guild
+func GuildDisplayPrefix(guildID string) string {
+    if p := GetGuildPrefix(guildID); p != "" {
+        return displayPrefix(p)
+    }
+    return displayPrefix(Prefixes()[len(Prefixes())-1])
+}
+
+// strips the bot mention, the guild's prefix, or one of the default prefixes from the start of the message.
+// the command patterns only ever see what's left. prefix is how the user should be told to type commands.
+func StripPrefix(s *discordgo.Session, m *discordgo.MessageCreate) (content string, prefix string, ok bool) {
+    if s.State.User != nil {
+        for _, mention := range([]string{ "<@" + s.State.User.ID + ">", "<@!" + s.State.User.ID + ">" }) {
+            if content, ok = matchPrefix(m.Content, mention); ok {
+                return content, mention + " ", true
+            }
+        }
+    }
+
+    // a guild with its own prefix only uses that one, since the defaults are probably what it's avoiding
+    if gp := GetGuildPrefix(m.GuildID); m.GuildID != "" && gp != "" {
+        content, ok = matchPrefix(m.Content, gp)
+        return content, displayPrefix(gp), ok
+    }
+
+    for _, p := range(Prefixes()) {
+        if content, ok = matchPrefix(m.Content, p); ok {
+            return content, displayPrefix(p), true
+        }
+    }
+
+    return "", "", false
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "sync"
+    "time"
+)
+
+// who shares a cooldown
+type CooldownScope int
+
+const (
+    ScopeUser CooldownScope = iota
+    ScopeChannel
+    ScopeGuild
+    ScopeGlobal
+)
+
+var scopeNames = map[CooldownScope]string {
+    ScopeUser: "user",
+    ScopeChannel: "channel",
+    ScopeGuild: "server",
+    ScopeGlobal: "everyone",
+}
+
+// a token bucket: up to Uses uses at once, refilling evenly over Per.
+// e.g. {Scope: ScopeUser, Uses: 3, Per: 30 * time.Second} lets each user burst 3 uses, then one more every 10 seconds.
+// commands with the same Bucket share their uses; without one, each command has its own.
+type Cooldown struct {
+    Bucket  string
+    Scope   CooldownScope
+    Uses    int
+    Per     time.Duration
+}
+
+func (c Cooldown) String() string {
+    uses := "uses"
+    if c.Uses == 1 {
+        uses = "use"
+    }
+    str := fmt.Sprintf("%v %v per %v per %v", c.Uses, uses, c.Per, scopeNames[c.Scope])
+    if c.Bucket != "" {
+        str += fmt.Sprintf(", shared by all %v commands", c.Bucket)
+    }
+    return str
+}
+
+// each bucket (or command)/scope/ID gets its own bucket
+func (c Cooldown) key(ctx *CommandContext) string {
+    id := ""
+    switch c.Scope {
+        case ScopeUser:
+            id = ctx.Author.ID
+        case ScopeChannel:
+            id = ctx.ChannelID
+        case ScopeGuild:
+            // DMs don't have a guild, so they count as their own
+            id = ctx.GuildID
+            if id == "" {
+                id = ctx.ChannelID
+            }
+    }
+    bucket := c.Bucket
+    if bucket == "" {
+        bucket = ctx.Command.Name
+    }
+    return fmt.Sprintf("%v/%v/%v", bucket, scopeNames[c.Scope], id)
+}
+
+func (c Cooldown) rate() float64 {
+    return float64(c.Uses) / c.Per.Seconds()
+}
+
+type tokenBucket struct {
+    cooldown    Cooldown // the one that made it, so Prune knows when it's full
+    tokens      float64
+    updated     time.Time
+}
+
+// refills the bucket for the time since it was last touched
+func (b *tokenBucket) refill(c Cooldown, now time.Time) {
+    b.tokens = math.Min(float64(c.Uses), b.tokens + now.Sub(b.updated).Seconds() * c.rate())
+    b.updated = now
+}
+
+type RateLimiter struct {
+    buckets map[string]*tokenBucket
+    lock    sync.Mutex
+}
+
+// the limiter used by CooldownMiddleware
+var Limiter = NewRateLimiter()
+
+func NewRateLimiter() *RateLimiter {
+    return &RateLimiter{
+        buckets: make(map[string]*tokenBucket),
+    }
+}
+
+// uses up one token from every cooldown's bucket if they all have one. if any of them
+// doesn't, nothing is used and the time until all of them would allow it is returned.
+func (rl *RateLimiter) Take(ctx *CommandContext, cooldowns []Cooldown) time.Duration {
+    rl.lock.Lock()
+    defer rl.lock.Unlock()
+
+    now := time.Now()
+    buckets := make([]*tokenBucket, len(cooldowns))
+    var wait time.Duration
+
+    for i, c := range(cooldowns) {
+        key := c.key(ctx)
+        b, ok := rl.buckets[key]
+        if !ok {
+            b = &tokenBucket{ cooldown: c, tokens: float64(c.Uses), updated: now }
+            rl.buckets[key] = b
+        }
+        b.refill(c, now)
+        buckets[i] = b
+
+        if b.tokens < 1 {
+            w := time.Duration((1 - b.tokens) / c.rate() * float64(time.Second))
+            if w > wait {
+                wait = w
+            }
+        }
+    }
+
+    if wait > 0 {
+        return wait
+    }
+
+    for _, b := range(buckets) {
+        b.tokens--
+    }
+    return 0
+}
+
+// forgets buckets that have refilled completely, since they're the same as new ones
+func (rl *RateLimiter) Prune() {
+    rl.lock.Lock()
+    defer rl.lock.Unlock()
+
+    now := time.Now()
+    for key, b := range(rl.buckets) {
+        c := b.cooldown
+        if b.tokens + now.Sub(b.updated).Seconds() * c.rate() >= float64(c.Uses) {
+            delete(rl.buckets, key)
+        }
+    }
+}
+
+// should only be run in a separate goroutine
+func (rl *RateLimiter) PruneRoutine() {
+    for {
+        time.Sleep(10 * time.Minute)
+        rl.Prune()
+    }
+}
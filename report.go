@@ -0,0 +1,99 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "runtime/debug"
+    "strings"
+    "sync"
+    "time"
+    "unicode/utf8"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// the same panic is only reported once this often; the rest are counted and mentioned next time
+const PanicReportCooldown = 10 * time.Minute
+
+type panicRecord struct {
+    reported    time.Time
+    suppressed  int
+}
+
+var panicReports = make(map[string]*panicRecord)
+var panicLock sync.Mutex
+
+// true if this panic should be posted, along with how many times it happened
+// without being posted since the last report
+func shouldReport(key string, now time.Time) (bool, int) {
+    panicLock.Lock()
+    defer panicLock.Unlock()
+
+    for k, p := range(panicReports) {
+        if now.Sub(p.reported) >= PanicReportCooldown && p.suppressed == 0 {
+            delete(panicReports, k)
+        }
+    }
+
+    p, ok := panicReports[key]
+    if !ok {
+        panicReports[key] = &panicRecord{ reported: now }
+        return true, 0
+    }
+    if now.Sub(p.reported) < PanicReportCooldown {
+        p.suppressed++
+        return false, 0
+    }
+    suppressed := p.suppressed
+    p.reported = now
+    p.suppressed = 0
+    return true, suppressed
+}
+
+// logs a recovered panic and posts it to the debug channel. where says what was running
+// (e.g. "command roll"), and trigger is the message that caused it, if there was one.
+func ReportPanic(s *discordgo.Session, r interface{}, stack []byte, where, trigger string) {
+    log.Printf("Panic in %v:\n%v\n%s\n", where, r, stack)
+
+    if s == nil || Config.DebugChannel == "" {
+        return
+    }
+    ok, suppressed := shouldReport(fmt.Sprintf("%v: %v", where, r), time.Now())
+    if !ok {
+        return
+    }
+
+    report := fmt.Sprintf("Recovered from a panic in %v: `%v`\n", truncateRunes(where, 100), truncateRunes(fmt.Sprint(r), 300))
+    if trigger != "" {
+        report += fmt.Sprintf("Triggered by: `%v`\n", strings.ReplaceAll(truncateRunes(trigger, 300), "`", "'"))
+    }
+    if suppressed > 0 {
+        report += fmt.Sprintf("This happened %v more times since it was last reported.\n", suppressed)
+    }
+    // keep the whole thing under discord's 2000 character limit
+    trace := truncateRunes(string(stack), 2000 - utf8.RuneCountInString(report) - 8)
+    report += "```\n" + trace + "```"
+
+    _, err := s.ChannelMessageSend(Config.DebugChannel, report)
+    if err != nil {
+        log.Printf("Error in ReportPanic:\n%v\n", err)
+    }
+}
+
+// cuts s down to at most n characters, ending in ... if anything was cut
+func truncateRunes(s string, n int) string {
+    if utf8.RuneCountInString(s) <= n {
+        return s
+    }
+    if n <= 3 {
+        return ""
+    }
+    return string([]rune(s)[:n-3]) + "..."
+}
+
+// deferred by event handlers that aren't commands, so a panic can't take down the bot
+func recoverEvent(s *discordgo.Session, where, trigger string) {
+    if r := recover(); r != nil {
+        ReportPanic(s, r, debug.Stack(), where, trigger)
+    }
+}
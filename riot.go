@@ -0,0 +1,155 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strconv"
+    "time"
+)
+
+const (
+    // %v is the host, either a platform like na1 or a region like americas
+    RiotBaseURL = "https://%v.api.riotgames.com"
+    // how long a League command gets to finish all of its requests
+    RiotTimeout = 10 * time.Second
+    // how many times a request is tried if riot keeps saying to slow down
+    RiotAttempts = 3
+)
+
+// what went wrong with a request, so callers can check with errors.Is
+var (
+    ErrRiotNotFound     = errors.New("not found")
+    ErrRiotRateLimited  = errors.New("rate limited")
+    ErrRiotForbidden    = errors.New("forbidden; the API key may have expired")
+    ErrRiotServer       = errors.New("riot server error")
+)
+
+// a non-200 response from the Riot API
+type RiotError struct {
+    StatusCode  int
+    Message     string
+    RetryAfter  time.Duration // only for 429s, and only if riot said
+}
+
+func (e *RiotError) Error() string {
+    if e.Message == "" {
+        return fmt.Sprintf("riot API returned %v", e.StatusCode)
+    }
+    return fmt.Sprintf("riot API returned %v: %v", e.StatusCode, e.Message)
+}
+
+// lets errors.Is match a RiotError against the Err* values above
+func (e *RiotError) Unwrap() error {
+    switch {
+        case e.StatusCode == http.StatusNotFound:
+            return ErrRiotNotFound
+        case e.StatusCode == http.StatusTooManyRequests:
+            return ErrRiotRateLimited
+        case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+            return ErrRiotForbidden
+        case e.StatusCode >= 500:
+            return ErrRiotServer
+    }
+    return nil
+}
+
+// talks to the Riot API. HTTP and BaseURL can be swapped out, e.g. to point it at a fake server,
+// and Limiter and Cache can be nil to not limit or cache anything
+type RiotClient struct {
+    Token   string
+    BaseURL string // a format string taking the host; see RiotBaseURL
+    HTTP    *http.Client
+    Limiter *RiotLimiter
+    Cache   *RiotCache
+}
+
+func NewRiotClient(token string, cache *RiotCache) *RiotClient {
+    return &RiotClient{
+        Token: token,
+        BaseURL: RiotBaseURL,
+        HTTP: &http.Client{ Timeout: RiotTimeout },
+        Limiter: NewRiotLimiter(),
+        Cache: cache,
+    }
+}
+
+// requests method (an endpoint like SUMMONER, filled in with args) from host and decodes the
+// JSON response into v. responses from endpoints in RiotCacheTTLs come from the cache while
+// they last. 429s are retried once the limiter allows it, as long as ctx has time left.
+func (c *RiotClient) Get(ctx context.Context, host, method string, v interface{}, args ...interface{}) error {
+    path := fmt.Sprintf(method, args...)
+    key := host + path
+    ttl := RiotCacheTTLs[method]
+    if c.Cache != nil && ttl > 0 && c.Cache.Get(key, v) {
+        return nil
+    }
+
+    for attempt := 1; ; attempt++ {
+        if c.Limiter != nil {
+            err := c.Limiter.Wait(ctx, host, method)
+            if err != nil {
+                return err
+            }
+        }
+
+        body, err := c.get(ctx, host, method, path)
+        if errors.Is(err, ErrRiotRateLimited) && c.Limiter != nil && attempt < RiotAttempts {
+            continue
+        }
+        if err != nil {
+            return err
+        }
+
+        err = json.Unmarshal(body, v)
+        if err != nil {
+            return fmt.Errorf("parsing response from %v: %v", path, err)
+        }
+        if c.Cache != nil && ttl > 0 {
+            c.Cache.Put(key, method, body, ttl)
+        }
+        return nil
+    }
+}
+
+// returns the body of a successful response
+func (c *RiotClient) get(ctx context.Context, host, method, path string) ([]byte, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(c.BaseURL, host) + path, nil)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("X-Riot-Token", c.Token)
+
+    resp, err := c.HTTP.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    var retryAfter time.Duration
+    if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+        retryAfter = time.Duration(secs) * time.Second
+    }
+    if c.Limiter != nil {
+        c.Limiter.Update(host, method, resp.Header, resp.StatusCode, retryAfter)
+    }
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    if resp.StatusCode != http.StatusOK {
+        rerr := &RiotError{ StatusCode: resp.StatusCode, RetryAfter: retryAfter }
+        // errors look like {"status": {"status_code": 404, "message": "Data not found"}}
+        var lerr GenericLeagueError
+        if json.Unmarshal(body, &lerr) == nil && lerr.Status != nil {
+            rerr.Message = lerr.Status.Message
+        }
+        return nil, rerr
+    }
+    return body, nil
+}
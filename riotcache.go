@@ -0,0 +1,177 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "os"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// how long responses from each endpoint are kept. endpoints that aren't here are never cached
+var RiotCacheTTLs = map[string]time.Duration {
+    ACCOUNT_BY_RIOT_ID: 10 * time.Minute,
+    ACCOUNT_BY_PUUID: 10 * time.Minute,
+    SUMMONER: 10 * time.Minute,
+    MASTERY_SCORE: 10 * time.Minute,
+    ALL_CHAMPION_MASTERY: 10 * time.Minute,
+    ALL_CHAMPION_MASTERY + BY_CHAMPION: 5 * time.Minute,
+    LEAGUE_ENTRIES: 5 * time.Minute,
+    MATCH_IDS: 2 * time.Minute,
+    // matches never change once they're over
+    MATCH: time.Hour,
+}
+
+type riotCacheEntry struct {
+    Method  string
+    Data    json.RawMessage
+    Expires time.Time
+}
+
+// remembers Riot API responses for a while. if it has a path, it's saved there
+// every so often and loaded again when the bot starts.
+type RiotCache struct {
+    entries map[string]*riotCacheEntry
+    hits    int
+    misses  int
+    path    string
+    lock    sync.Mutex
+}
+
+// path can be "" to only keep the cache in memory
+func NewRiotCache(path string) *RiotCache {
+    c := &RiotCache{
+        entries: make(map[string]*riotCacheEntry),
+        path: path,
+    }
+    if path == "" {
+        return c
+    }
+
+    fcontents, err := ioutil.ReadFile(path)
+    if os.IsNotExist(err) {
+        return c
+    } else if err != nil {
+        log.Printf("Error in NewRiotCache:\n%v\n", err)
+        return c
+    }
+    err = json.Unmarshal(fcontents, &c.entries)
+    if err != nil {
+        log.Printf("Error parsing %v:\n%v\nStarting with an empty cache.\n", path, err)
+        c.entries = make(map[string]*riotCacheEntry)
+    }
+    return c
+}
+
+// decodes the cached response into v; false if there isn't one or it's expired
+func (c *RiotCache) Get(key string, v interface{}) bool {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    e, ok := c.entries[key]
+    if !ok || time.Now().After(e.Expires) || json.Unmarshal(e.Data, v) != nil {
+        c.misses++
+        return false
+    }
+    c.hits++
+    return true
+}
+
+func (c *RiotCache) Put(key, method string, data []byte, ttl time.Duration) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    c.entries[key] = &riotCacheEntry{
+        Method: method,
+        Data: json.RawMessage(data),
+        Expires: time.Now().Add(ttl),
+    }
+}
+
+// forgets everything; returns how many entries there were
+func (c *RiotCache) Flush() int {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    n := len(c.entries)
+    c.entries = make(map[string]*riotCacheEntry)
+    c.hits = 0
+    c.misses = 0
+    return n
+}
+
+// drops expired entries and saves the rest, if there's somewhere to save them
+func (c *RiotCache) Prune() {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    now := time.Now()
+    for key, e := range(c.entries) {
+        if now.After(e.Expires) {
+            delete(c.entries, key)
+        }
+    }
+
+    if c.path == "" {
+        return
+    }
+    file, err := json.Marshal(c.entries)
+    if err == nil {
+        err = ioutil.WriteFile(c.path, file, 0644)
+    }
+    if err != nil {
+        log.Printf("Error in RiotCache.Prune:\n%v\n", err)
+    }
+}
+
+// should only be run in a separate goroutine
+func (c *RiotCache) PruneRoutine() {
+    for {
+        time.Sleep(5 * time.Minute)
+        c.Prune()
+    }
+}
+
+// for the "lol cache" command
+func (c *RiotCache) StatsEmbed() *discordgo.MessageEmbed {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    now := time.Now()
+    live := make(map[string]int)
+    for _, e := range(c.entries) {
+        if now.Before(e.Expires) {
+            live[e.Method]++
+        }
+    }
+
+    embed := &discordgo.MessageEmbed{
+        Title: "League Cache",
+        Description: fmt.Sprintf("%v entries, %v hits, %v misses", len(c.entries), c.hits, c.misses),
+    }
+    if total := c.hits + c.misses; total > 0 {
+        embed.Description += fmt.Sprintf(" (%.0f%% hit rate)", float64(c.hits) / float64(total) * 100)
+    }
+    if c.path != "" {
+        embed.Description += "\nSaved to " + c.path
+    }
+
+    methods := make([]string, 0, len(RiotCacheTTLs))
+    for m := range(RiotCacheTTLs) {
+        methods = append(methods, m)
+    }
+    sort.Strings(methods)
+    for _, m := range(methods) {
+        embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+            Name: m,
+            Value: fmt.Sprintf("%v live, kept for %v", live[m], RiotCacheTTLs[m]),
+            Inline: false,
+        })
+    }
+    return embed
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// what a development key gets, used until riot tells us otherwise
+const DefaultRiotAppLimits = "20:1,100:120"
+
+// one "limit:seconds" pair from a rate limit header; at most limit requests per per
+type riotWindow struct {
+    limit   int
+    per     time.Duration
+    count   int
+    start   time.Time
+}
+
+// parses headers like X-App-Rate-Limit: 20:1,100:120 and X-App-Rate-Limit-Count: 1:1,1:120
+func parseRiotWindows(header string) []*riotWindow {
+    var windows []*riotWindow
+    for _, pair := range(strings.Split(header, ",")) {
+        parts := strings.Split(strings.TrimSpace(pair), ":")
+        if len(parts) != 2 {
+            continue
+        }
+        n, err1 := strconv.Atoi(parts[0])
+        secs, err2 := strconv.Atoi(parts[1])
+        if err1 != nil || err2 != nil || secs <= 0 {
+            continue
+        }
+        windows = append(windows, &riotWindow{ limit: n, per: time.Duration(secs) * time.Second })
+    }
+    return windows
+}
+
+// the app limit for a host, or one method's limit on a host
+type riotBucket struct {
+    windows     []*riotWindow
+    blocked     time.Time // riot said to back off until then
+}
+
+// how long until a request fits in every window
+func (b *riotBucket) wait(now time.Time) time.Duration {
+    var wait time.Duration
+    if now.Before(b.blocked) {
+        wait = b.blocked.Sub(now)
+    }
+    for _, w := range(b.windows) {
+        if w.count >= w.limit && now.Sub(w.start) < w.per {
+            if d := w.start.Add(w.per).Sub(now); d > wait {
+                wait = d
+            }
+        }
+    }
+    return wait
+}
+
+func (b *riotBucket) take(now time.Time) {
+    for _, w := range(b.windows) {
+        if w.start.IsZero() || now.Sub(w.start) >= w.per {
+            w.start = now
+            w.count = 0
+        }
+        w.count++
+    }
+}
+
+// takes on the limits riot sent, keeping what we've counted for windows that didn't change,
+// and trusts riot's counts if they're higher than ours
+func (b *riotBucket) update(limits, counts string, now time.Time) {
+    if limits != "" {
+        windows := parseRiotWindows(limits)
+        for _, w := range(windows) {
+            for _, old := range(b.windows) {
+                if old.per == w.per {
+                    w.count = old.count
+                    w.start = old.start
+                }
+            }
+        }
+        b.windows = windows
+    }
+    for _, c := range(parseRiotWindows(counts)) {
+        for _, w := range(b.windows) {
+            if w.per == c.per && c.limit > w.count {
+                w.count = c.limit
+                if w.start.IsZero() {
+                    w.start = now
+                }
+            }
+        }
+    }
+}
+
+// keeps requests under riot's app limits (per host) and method limits (per host and endpoint),
+// so a busy server can't get the key blacklisted
+type RiotLimiter struct {
+    buckets map[string]*riotBucket
+    lock    sync.Mutex
+}
+
+func NewRiotLimiter() *RiotLimiter {
+    return &RiotLimiter{
+        buckets: make(map[string]*riotBucket),
+    }
+}
+
+// must be called with the lock held
+func (rl *RiotLimiter) bucket(key string) *riotBucket {
+    b, ok := rl.buckets[key]
+    if !ok {
+        b = &riotBucket{}
+        // method limits are only known once riot sends them
+        if !strings.Contains(key, "/") {
+            b.windows = parseRiotWindows(DefaultRiotAppLimits)
+        }
+        rl.buckets[key] = b
+    }
+    return b
+}
+
+// waits until a request to method on host is allowed, then counts it. if that would take
+// longer than ctx allows, it gives up right away with ErrRiotRateLimited.
+func (rl *RiotLimiter) Wait(ctx context.Context, host, method string) error {
+    for {
+        rl.lock.Lock()
+        now := time.Now()
+        app := rl.bucket(host)
+        meth := rl.bucket(host + method)
+        wait := app.wait(now)
+        if w := meth.wait(now); w > wait {
+            wait = w
+        }
+        if wait == 0 {
+            app.take(now)
+            meth.take(now)
+            rl.lock.Unlock()
+            return nil
+        }
+        rl.lock.Unlock()
+
+        if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+            return &RiotError{ StatusCode: http.StatusTooManyRequests, RetryAfter: wait }
+        }
+        timer := time.NewTimer(wait)
+        select {
+            case <-ctx.Done():
+                timer.Stop()
+                return ctx.Err()
+            case <-timer.C:
+        }
+    }
+}
+
+// reads the rate limit headers from a response. on a 429, whichever limit riot says was
+// hit is blocked for retryAfter.
+func (rl *RiotLimiter) Update(host, method string, h http.Header, status int, retryAfter time.Duration) {
+    rl.lock.Lock()
+    defer rl.lock.Unlock()
+
+    now := time.Now()
+    app := rl.bucket(host)
+    meth := rl.bucket(host + method)
+    app.update(h.Get("X-App-Rate-Limit"), h.Get("X-App-Rate-Limit-Count"), now)
+    meth.update(h.Get("X-Method-Rate-Limit"), h.Get("X-Method-Rate-Limit-Count"), now)
+
+    if status == http.StatusTooManyRequests {
+        if retryAfter <= 0 {
+            retryAfter = time.Second
+        }
+        switch h.Get("X-Rate-Limit-Type") {
+            case "application":
+                app.blocked = now.Add(retryAfter)
+            default:
+                // "method", and "service" (riot's own servers being overloaded), which also only affects this endpoint
+                meth.blocked = now.Add(retryAfter)
+        }
+    }
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+)
+
+// windows written back out like riot's headers, e.g. "20:1s,100:2m0s"
+func formatWindows(windows []*riotWindow) string {
+    var parts []string
+    for _, w := range(windows) {
+        parts = append(parts, fmt.Sprintf("%v:%v", w.limit, w.per))
+    }
+    return strings.Join(parts, ",")
+}
+
+func TestParseRiotWindows(t *testing.T) {
+    headers := map[string]string {
+        "20:1,100:120": "20:1s,100:2m0s",
+        "1:1, 1:120": "1:1s,1:2m0s",
+        // bad pairs are skipped, not the whole header
+        "junk,30:10": "30:10s",
+        "a:1,20:b,5:0,5:-1": "",
+        "20:1:5": "",
+        "": "",
+    }
+    for header, want := range(headers) {
+        if got := formatWindows(parseRiotWindows(header)); got != want {
+            t.Errorf("parseRiotWindows(%q) = %q, want %q", header, got, want)
+        }
+    }
+}
+
+// what X-Method-Rate-Limit and X-Method-Rate-Limit-Count do to a method's bucket
+func TestRiotBucketUpdate(t *testing.T) {
+    now := time.Now()
+    b := &riotBucket{}
+    b.update("2:10", "2:10", now)
+    if wait := b.wait(now); wait != 10 * time.Second {
+        t.Errorf("after riot counted 2 of 2, wait = %v, want 10s", wait)
+    }
+
+    // a new limit for the same window keeps the count, and lower counts from riot don't replace ours
+    b.update("3:10", "1:10", now)
+    if wait := b.wait(now); wait != 0 {
+        t.Errorf("with 2 of 3 used, wait = %v, want 0", wait)
+    }
+    b.take(now)
+    if wait := b.wait(now.Add(time.Second)); wait != 9 * time.Second {
+        t.Errorf("with 3 of 3 used, wait = %v, want 9s", wait)
+    }
+    if wait := b.wait(now.Add(10 * time.Second)); wait != 0 {
+        t.Errorf("once the window is over, wait = %v, want 0", wait)
+    }
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+    "log"
+)
+
+// store buckets
+const (
+    GuildBucket = "guilds"
+    UserBucket  = "users"
+)
+
+// the store everything below reads from and writes to; opened in init()
+var DB *Store
+
+// settings that guild admins can change for their own server
+type GuildSettings struct {
+    Prefix  string      `json:",omitempty"` // if "", the default prefixes are used
+    Dad     DadSettings
+    Roles   map[string]*RoleRules `json:",omitempty"` // by command name
+    LeagueRegion    string  `json:",omitempty"` // if "", DefaultLeagueRegion is used
+    LeagueAnnounceChannel   string  `json:",omitempty"` // where rank changes are posted; if "", they aren't
+}
+
+// things the bot remembers about individual users, across every guild
+type UserData struct {
+    DadOptOut   bool    `json:",omitempty"`
+    League      *LeagueLink `json:",omitempty"` // nil if they haven't linked an account
+}
+
+// returns the defaults if the guild has never changed anything
+func GetGuildSettings(guildID string) GuildSettings {
+    var gs GuildSettings
+    _, err := DB.Get(GuildBucket, guildID, &gs)
+    if err != nil {
+        log.Printf("Error in GetGuildSettings:\n%v\n", err)
+    }
+    return gs
+}
+
+// fn changes the settings in place; they're saved once it returns
+func UpdateGuildSettings(guildID string, fn func(*GuildSettings)) error {
+    var gs GuildSettings
+    return DB.Update(GuildBucket, guildID, &gs, func() error {
+        fn(&gs)
+        return nil
+    })
+}
+
+func GetUserData(userID string) UserData {
+    var ud UserData
+    _, err := DB.Get(UserBucket, userID, &ud)
+    if err != nil {
+        log.Printf("Error in GetUserData:\n%v\n", err)
+    }
+    return ud
+}
+
+func UpdateUserData(userID string, fn func(*UserData)) error {
+    var ud UserData
+    return DB.Update(UserBucket, userID, &ud, func() error {
+        fn(&ud)
+        return nil
+    })
+}
+
+// returns "" if the guild uses the default prefixes
+func GetGuildPrefix(guildID string) string {
+    if guildID == "" {
+        return ""
+    }
+    return GetGuildSettings(guildID).Prefix
+}
+
+// setting it to "" goes back to the default prefixes
+func SetGuildPrefix(guildID, prefix string) error {
+    return UpdateGuildSettings(guildID, func(gs *GuildSettings) {
+        gs.Prefix = prefix
+    })
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "os"
+    "sort"
+    "sync"
+)
+
+const StoreFile = "store.json"
+
+// Migrations[i] takes the store from schema version i to i+1, so the current version is len(Migrations).
+// add one to the end whenever stored data changes shape. version 0 is the first one: guild settings
+// (prefixes and all) in GuildBucket, users in UserBucket
+var Migrations = []func(*Store) error {
+}
+
+// a small key/value store that keeps everything in memory and writes it all to one json file.
+// values are grouped into buckets, and each value is stored as json so any type can go in.
+type Store struct {
+    path    string // if "", nothing is ever written to disk
+    lock    sync.RWMutex
+
+    data    storeData
+}
+
+type storeData struct {
+    Version int                                     `json:"version"`
+    Buckets map[string]map[string]json.RawMessage   `json:"buckets"`
+}
+
+// loads the store from path (creating it if it doesn't exist) and runs any migrations it needs
+func OpenStore(path string) (*Store, error) {
+    return openStore(path, Migrations)
+}
+
+// OpenStore with another migration chain; the schema version is len(migrations)
+func openStore(path string, migrations []func(*Store) error) (*Store, error) {
+    st := &Store{
+        path: path,
+        data: storeData{
+            Buckets: make(map[string]map[string]json.RawMessage),
+        },
+    }
+
+    fcontents, err := ioutil.ReadFile(path)
+    if err == nil {
+        err = json.Unmarshal(fcontents, &st.data)
+        if err != nil {
+            return nil, fmt.Errorf("parsing %v: %v", path, err)
+        }
+        if st.data.Buckets == nil {
+            st.data.Buckets = make(map[string]map[string]json.RawMessage)
+        }
+    } else if os.IsNotExist(err) {
+        // a new store has nothing to migrate
+        st.data.Version = len(migrations)
+    } else {
+        return nil, err
+    }
+
+    if st.data.Version > len(migrations) {
+        return nil, fmt.Errorf("%v has schema version %v, but this build only knows about version %v", path, st.data.Version, len(migrations))
+    }
+
+    for st.data.Version < len(migrations) {
+        log.Printf("Migrating store from schema version %v to %v\n", st.data.Version, st.data.Version + 1)
+        err = migrations[st.data.Version](st)
+        if err != nil {
+            return nil, fmt.Errorf("migrating to schema version %v: %v", st.data.Version + 1, err)
+        }
+        st.data.Version++
+    }
+
+    st.lock.Lock()
+    defer st.lock.Unlock()
+    return st, st.flush()
+}
+
+// a store that's never written to disk; handy if the real one can't be used
+func NewMemoryStore() *Store {
+    return &Store{
+        data: storeData{
+            Version: len(Migrations),
+            Buckets: make(map[string]map[string]json.RawMessage),
+        },
+    }
+}
+
+// assumes the lock is held. writes to a temp file first so a crash can't leave half a file behind
+func (st *Store) flush() error {
+    if st.path == "" {
+        return nil
+    }
+
+    file, err := json.MarshalIndent(st.data, "", "\t")
+    if err != nil {
+        return err
+    }
+
+    tmp := st.path + ".tmp"
+    err = ioutil.WriteFile(tmp, file, 0644)
+    if err != nil {
+        return err
+    }
+    return os.Rename(tmp, st.path)
+}
+
+// assumes the lock is held
+func (st *Store) get(bucket, key string, v interface{}) (bool, error) {
+    raw, ok := st.data.Buckets[bucket][key]
+    if !ok {
+        return false, nil
+    }
+    return true, json.Unmarshal(raw, v)
+}
+
+// assumes the lock is held; doesn't flush
+func (st *Store) put(bucket, key string, v interface{}) error {
+    raw, err := json.Marshal(v)
+    if err != nil {
+        return err
+    }
+    if st.data.Buckets[bucket] == nil {
+        st.data.Buckets[bucket] = make(map[string]json.RawMessage)
+    }
+    st.data.Buckets[bucket][key] = raw
+    return nil
+}
+
+// unmarshals the value into v. returns false if there was no such key, in which case v is left alone
+func (st *Store) Get(bucket, key string, v interface{}) (bool, error) {
+    st.lock.RLock()
+    defer st.lock.RUnlock()
+    return st.get(bucket, key, v)
+}
+
+func (st *Store) Put(bucket, key string, v interface{}) error {
+    st.lock.Lock()
+    defer st.lock.Unlock()
+    err := st.put(bucket, key, v)
+    if err != nil {
+        return err
+    }
+    return st.flush()
+}
+
+// loads the value into v (if there is one), calls fn, and stores v again, all without
+// letting anyone else touch the store in between. nothing is stored if fn returns an error.
+func (st *Store) Update(bucket, key string, v interface{}, fn func() error) error {
+    st.lock.Lock()
+    defer st.lock.Unlock()
+    _, err := st.get(bucket, key, v)
+    if err != nil {
+        return err
+    }
+    err = fn()
+    if err != nil {
+        return err
+    }
+    err = st.put(bucket, key, v)
+    if err != nil {
+        return err
+    }
+    return st.flush()
+}
+
+func (st *Store) Delete(bucket, key string) error {
+    st.lock.Lock()
+    defer st.lock.Unlock()
+    if _, ok := st.data.Buckets[bucket][key]; !ok {
+        return nil
+    }
+    delete(st.data.Buckets[bucket], key)
+    return st.flush()
+}
+
+// sorted, so callers get the same order every time
+func (st *Store) Keys(bucket string) []string {
+    st.lock.RLock()
+    defer st.lock.RUnlock()
+    keys := make([]string, 0, len(st.data.Buckets[bucket]))
+    for k := range(st.data.Buckets[bucket]) {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
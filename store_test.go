@@ -0,0 +1,138 @@
+package main
+
+import (
+    "errors"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// init opens the real store; tests get a memory one so they never write bot data
+func TestMain(m *testing.M) {
+    DB = NewMemoryStore()
+    os.Exit(m.Run())
+}
+
+// a store file at some schema version in a temp dir, or just the path if contents is ""
+func storeFile(t *testing.T, contents string) string {
+    path := filepath.Join(t.TempDir(), StoreFile)
+    if contents != "" {
+        if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return path
+}
+
+// migrations that each write down that they ran in *ran
+func recordingMigrations(ran *[]int, n int) []func(*Store) error {
+    var migrations []func(*Store) error
+    for i := 0; i < n; i++ {
+        i := i
+        migrations = append(migrations, func(st *Store) error {
+            *ran = append(*ran, i)
+            return nil
+        })
+    }
+    return migrations
+}
+
+func TestOpenStoreNew(t *testing.T) {
+    var ran []int
+    path := storeFile(t, "")
+    st, err := openStore(path, recordingMigrations(&ran, 3))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ran) != 0 {
+        t.Errorf("a new store ran migrations %v", ran)
+    }
+    if st.data.Version != 3 {
+        t.Errorf("a new store is at version %v, want 3", st.data.Version)
+    }
+    if _, err := os.Stat(path); err != nil {
+        t.Errorf("the file wasn't created: %v", err)
+    }
+}
+
+func TestOpenStoreMigrates(t *testing.T) {
+    var ran []int
+    path := storeFile(t, `{"version":1,"buckets":{}}`)
+    migrations := recordingMigrations(&ran, 3)
+    if _, err := openStore(path, migrations); err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(ran, []int{ 1, 2 }) {
+        t.Errorf("ran migrations %v, want [1 2]", ran)
+    }
+
+    // the new version is saved, so nothing runs the second time
+    ran = nil
+    st, err := openStore(path, migrations)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ran) != 0 || st.data.Version != 3 {
+        t.Errorf("reopening ran %v and ended at version %v", ran, st.data.Version)
+    }
+}
+
+func TestOpenStoreMigrationFails(t *testing.T) {
+    const contents = `{"version":0,"buckets":{}}`
+    path := storeFile(t, contents)
+    migrations := []func(*Store) error {
+        func(st *Store) error {
+            return st.put(GuildBucket, "1", &GuildSettings{ Prefix: "!" })
+        },
+        func(st *Store) error {
+            return errors.New("broken")
+        },
+    }
+    if _, err := openStore(path, migrations); err == nil {
+        t.Fatal("a failed migration should fail OpenStore")
+    }
+    // the first migration's changes mustn't be saved without the second's
+    if data, _ := ioutil.ReadFile(path); string(data) != contents {
+        t.Errorf("the file changed to %s", data)
+    }
+}
+
+func TestOpenStoreTooNew(t *testing.T) {
+    path := storeFile(t, `{"version":2,"buckets":{}}`)
+    if _, err := openStore(path, make([]func(*Store) error, 1)); err == nil {
+        t.Error("a store from a newer build should be refused")
+    }
+}
+
+func TestOpenStoreBadJSON(t *testing.T) {
+    path := storeFile(t, `{"version":`)
+    if _, err := openStore(path, nil); err == nil {
+        t.Error("a broken file should be refused, not overwritten")
+    }
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+    path := storeFile(t, "")
+    st, err := openStore(path, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := st.Put(GuildBucket, "1", &GuildSettings{ Prefix: "!" }); err != nil {
+        t.Fatal(err)
+    }
+
+    st, err = openStore(path, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    gs := &GuildSettings{}
+    ok, err := st.Get(GuildBucket, "1", gs)
+    if err != nil || !ok || gs.Prefix != "!" {
+        t.Errorf("got %+v, %v, %v", gs, ok, err)
+    }
+    if ok, _ := st.Get(GuildBucket, "2", gs); ok {
+        t.Error("found a key that was never put")
+    }
+}
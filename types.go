@@ -7,22 +7,80 @@ import (
 
 /* Commands */
 
-type MsgHandler func(*discordgo.MessageCreate, *discordgo.Session)
+type MsgHandler func(*CommandContext, *discordgo.Session, *CommandArgs)
+
+// where a command came from and how to reply to it; this is the same for text and slash commands
+type CommandContext struct {
+    Session     *discordgo.Session
+    Message     *discordgo.MessageCreate // nil for slash commands
+    Interaction *discordgo.InteractionCreate // nil for text commands
+    Author      *discordgo.User
+    ChannelID   string
+    GuildID     string
+    Prefix      string // how the user typed the command, e.g. "c " or "/"
+    Command     *Command
+    Failed      bool // set if the handler panicked
+
+    argtext     string // text commands: everything after the command name
+    options     []*discordgo.ApplicationCommandInteractionDataOption // slash commands: the options discord parsed
+    acked       bool // whether or not the interaction has been responded to (or deferred) yet
+    replied     bool // whether or not the interaction response has actual content yet
+}
+
+// runs a command for a context; middleware wraps these around each other
+type CommandFunc func(*CommandContext)
+
+// takes the rest of the chain and returns a function that does something before and/or after it
+type Middleware func(next CommandFunc) CommandFunc
 
 type Command struct {
-    Pattern     *regexp.Regexp
+    Pattern     *regexp.Regexp // matches the command name only; arguments are parsed from whatever follows
     Name        string
     Args        []CommandArg
     Examples    []string
     Description string
+    Summary     string // a description short enough for slash commands; Description is used if it's empty
     Aliases     []string
     Handler     MsgHandler
     Category    string // if "" the command won't be listed in help menu
-    AdminOnly   bool
+    AdminOnly   bool // only bot admins can use it, in any guild
+    Permissions int64 // discord permissions the user needs in the channel; guilds can change this with role lists
     NoTyping    bool // whether or not the command should show the bot as "typing"
+    GuildOnly   bool // the command can't be used in DMs
+    DMOnly      bool // the command can only be used in DMs
+    Cooldowns   []Cooldown // every one of these has to allow a use; bot admins skip them
+    Middleware  []Middleware // extra middleware, run after DefaultMiddleware and right before the handler
 }
 
+/* Arguments */
+
+type ArgType int
+
+const (
+    ArgString ArgType = iota // a single word, or a "quoted string"
+    ArgInt
+    ArgUser // a user mention, or a raw user ID
+    ArgChannel // a channel mention, or a raw channel ID
+    ArgRole // a role mention, or a raw role ID
+    ArgRegion // a League region like euw or region:euw; if it's optional, not last, and the word isn't a region, it's left for the next argument
+    ArgRest // everything left on the line; arguments after it only come from slash commands
+    ArgSummoner // a summoner's Riot ID like Some Name#TAG, which can have spaces before the #, or a single word like ArgString
+)
+
 type CommandArg struct {
     Title       string
     Required    bool
+    Type        ArgType
+}
+
+// the arguments parsed for a single command invocation
+type CommandArgs struct {
+    Raw     string // everything after the command name, trimmed
+    values  map[string]interface{}
+}
+
+// returned when the arguments don't match the command's Args
+type ArgError struct {
+    Arg     *CommandArg // nil if the error isn't about a specific argument
+    Reason  string
 }